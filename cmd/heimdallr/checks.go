@@ -0,0 +1,31 @@
+package main
+
+import (
+	heimdallrv1 "github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	heimdallrclient "github.com/jeromefroe/heimdallr/pkg/client/clientset/versioned/typed/heimdallr/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// checkLister lists the checks matching a selector from the API server. Only checks which also
+// match the selector heimdallr watches are listed.
+type checkLister struct {
+	checks   heimdallrclient.HTTPChecksGetter
+	selector string
+}
+
+func (l checkLister) ListChecks(namespace, selector string) ([]heimdallrv1.HTTPCheck, error) {
+	switch {
+	case l.selector == "":
+	case selector == "":
+		selector = l.selector
+	default:
+		selector = l.selector + "," + selector
+	}
+
+	list, err := l.checks.HTTPChecks(namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
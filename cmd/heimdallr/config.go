@@ -0,0 +1,248 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/config"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// loadConfig loads the configuration of the controller from the file given by the --config
+// flag, if any, overriding its values with those of any flags which are set. Credentials which
+// aren't configured by either are read from the environment.
+func loadConfig(fs *flag.FlagSet, args []string) (config.Config, error) {
+	var (
+		defaults = config.Default()
+		path     = fs.String("config", "", "Path to a YAML configuration file, overridden by any flags which are set")
+
+		username   = fs.String("username", "", "Pingdom Username, defaults to $PINGDOM_USERNAME")
+		password   = fs.String("password", "", "Pingdom Password, defaults to $PINGDOM_PASSWORD")
+		appkey     = fs.String("appkey", "", "Pingdom Application Key, defaults to $PINGDOM_APPKEY")
+		token      = fs.String("token", "", "Pingdom API Token, used instead of the username, password and application key, defaults to $PINGDOM_TOKEN")
+		contactIDs = fs.String("contact-ids", "", "Comma separated IDs of the Pingdom users alerted by checks, defaults to $PINGDOM_CONTACT_IDS")
+
+		credentialsDir      = fs.String("credentials-dir", "", "Directory containing a file for each Pingdom credential, reloaded when changed")
+		credentialsInterval = fs.Duration("credentials-interval", defaults.Credentials.ReloadInterval.Duration, "Interval at which the credentials directory is checked for changes")
+
+		namespaces = fs.String("namespaces", "", "Comma separated namespaces to watch, all namespaces are watched if empty")
+		selector   = fs.String("selector", "", "Label selector restricting the resources watched")
+
+		cluster      = fs.String("cluster", "", "Identifier of the cluster, set when several clusters share a Pingdom account")
+		nameTemplate = fs.String("name-template", "", "Go template used to name checks in Pingdom, such as '{{.Namespace}}/{{.Name}} {{.Spec.Hostname}}'")
+
+		labelTagPrefix = fs.String("label-tag-prefix", "", "Labels whose key starts with the prefix are added to checks as Pingdom tags")
+		labelTags      = fs.String("label-tags", "", "Comma separated keys of labels added to checks as Pingdom tags")
+		integrations   = fs.String("integrations", "", "Comma separated integrations checks can reference by name, as <name>=<id>")
+
+		workers           = fs.Int("workers", defaults.Workers, "Number of checks processed concurrently")
+		resyncPeriod      = fs.Duration("resync-period", defaults.ResyncPeriod.Duration, "Interval at which watched resources are resynced, disabled if zero")
+		reconcileInterval = fs.Duration("reconcile-interval", defaults.ReconcileInterval.Duration, "Interval at which checks are listed to delete those removed without heimdallr noticing, disabled if zero")
+
+		statusInterval = fs.Duration("status-interval", defaults.StatusInterval.Duration, "Interval at which the state of checks in Pingdom is copied into their status, disabled if zero")
+		uptimeWindows  = fs.String("uptime-windows", formatDurations(defaults.Uptime.Windows), "Comma separated windows over which the uptime of checks is recorded, disabled if empty")
+		uptimeRefresh  = fs.Duration("uptime-refresh", defaults.Uptime.RefreshInterval.Duration, "Interval at which the uptime of each check is read from Pingdom")
+
+		rateLimit = fs.Float64("rate-limit", defaults.RateLimit.RequestsPerSecond, "Maximum number of requests per second made to Pingdom")
+		rateBurst = fs.Int("rate-burst", defaults.RateLimit.Burst, "Maximum number of requests made to Pingdom in a burst")
+
+		ingressDiscovery = fs.Bool("ingress-discovery", false, "Generate checks from annotated Ingresses")
+		serviceDiscovery = fs.Bool("service-discovery", false, "Generate checks from annotated LoadBalancer Services")
+		alerting         = fs.Bool("alerting", false, "Manage Pingdom contacts and teams from PingdomContact and PingdomTeam resources")
+		maintenance      = fs.Bool("maintenance", false, "Manage Pingdom maintenance windows from MaintenanceWindow resources")
+		rolloutPause     = fs.Bool("rollout-pause", false, "Pause checks while the Deployment named by their pause-during-rollout annotation rolls out")
+		deleteOrphans    = fs.Bool("delete-orphans", false, "Delete the checks in Pingdom tagged with the cluster whose HTTPCheck no longer exists when reconciling")
+
+		dryRun         = fs.Bool("dry-run", false, "Log the changes that would be made to Pingdom instead of making them")
+		metricsAddress = fs.String("metrics-address", defaults.MetricsAddress, "Address to serve Prometheus metrics and readiness on")
+	)
+	if err := fs.Parse(args); err != nil {
+		return config.Config{}, err
+	}
+
+	cfg := defaults
+	if *path != "" {
+		var err error
+		if cfg, err = config.Load(*path); err != nil {
+			return config.Config{}, err
+		}
+	}
+
+	// The first invalid flag is reported, later flags which parse don't reset the error.
+	var err error
+	fail := func(e error, msg string) {
+		if e != nil && err == nil {
+			err = fmt.Errorf("%s: %v", msg, e)
+		}
+	}
+	fs.Visit(func(f *flag.Flag) {
+		var e error
+		switch f.Name {
+		case "username":
+			cfg.Credentials.Username = *username
+		case "password":
+			cfg.Credentials.Password = *password
+		case "appkey":
+			cfg.Credentials.AppKey = *appkey
+		case "token":
+			cfg.Credentials.Token = *token
+		case "contact-ids":
+			cfg.Credentials.ContactIDs, e = parseIDs(*contactIDs)
+			fail(e, "invalid contact IDs")
+		case "credentials-dir":
+			cfg.Credentials.Dir = *credentialsDir
+		case "credentials-interval":
+			cfg.Credentials.ReloadInterval.Duration = *credentialsInterval
+		case "namespaces":
+			cfg.Namespaces = splitList(*namespaces)
+		case "selector":
+			cfg.Selector = *selector
+		case "cluster":
+			cfg.Cluster = *cluster
+		case "name-template":
+			cfg.NameTemplate = *nameTemplate
+		case "label-tag-prefix":
+			cfg.LabelTags.Prefix = *labelTagPrefix
+		case "label-tags":
+			cfg.LabelTags.Keys = splitList(*labelTags)
+		case "integrations":
+			cfg.Integrations, e = parseIntegrations(*integrations)
+			fail(e, "invalid integrations")
+		case "workers":
+			cfg.Workers = *workers
+		case "resync-period":
+			cfg.ResyncPeriod.Duration = *resyncPeriod
+		case "reconcile-interval":
+			cfg.ReconcileInterval.Duration = *reconcileInterval
+		case "status-interval":
+			cfg.StatusInterval.Duration = *statusInterval
+		case "uptime-windows":
+			cfg.Uptime.Windows, e = parseDurations(*uptimeWindows)
+			fail(e, "invalid uptime windows")
+		case "uptime-refresh":
+			cfg.Uptime.RefreshInterval.Duration = *uptimeRefresh
+		case "rate-limit":
+			cfg.RateLimit.RequestsPerSecond = *rateLimit
+		case "rate-burst":
+			cfg.RateLimit.Burst = *rateBurst
+		case "ingress-discovery":
+			cfg.Features.IngressDiscovery = *ingressDiscovery
+		case "service-discovery":
+			cfg.Features.ServiceDiscovery = *serviceDiscovery
+		case "alerting":
+			cfg.Features.Alerting = *alerting
+		case "maintenance":
+			cfg.Features.Maintenance = *maintenance
+		case "rollout-pause":
+			cfg.Features.RolloutPause = *rolloutPause
+		case "delete-orphans":
+			cfg.Features.DeleteOrphans = *deleteOrphans
+		case "dry-run":
+			cfg.Features.DryRun = *dryRun
+		case "metrics-address":
+			cfg.MetricsAddress = *metricsAddress
+		}
+	})
+	if err != nil {
+		return config.Config{}, err
+	}
+
+	if err := credentialsFromEnv(&cfg.Credentials); err != nil {
+		return config.Config{}, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+func credentialsFromEnv(creds *config.Credentials) error {
+	for _, v := range []struct {
+		value *string
+		env   string
+	}{
+		{&creds.Username, "PINGDOM_USERNAME"},
+		{&creds.Password, "PINGDOM_PASSWORD"},
+		{&creds.AppKey, "PINGDOM_APPKEY"},
+		{&creds.Token, "PINGDOM_TOKEN"},
+	} {
+		if *v.value == "" {
+			*v.value = os.Getenv(v.env)
+		}
+	}
+
+	if len(creds.ContactIDs) == 0 {
+		ids, err := parseIDs(os.Getenv("PINGDOM_CONTACT_IDS"))
+		if err != nil {
+			return fmt.Errorf("invalid PINGDOM_CONTACT_IDS: %v", err)
+		}
+		creds.ContactIDs = ids
+	}
+	return nil
+}
+
+// splitList splits a comma separated list, ignoring empty elements.
+func splitList(s string) []string {
+	var list []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// parseIDs parses a comma separated list of IDs.
+func parseIDs(s string) ([]int, error) {
+	var ids []int
+	for _, e := range splitList(s) {
+		id, err := strconv.Atoi(e)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID %q: %v", e, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// formatDurations formats durations as a comma separated list which parseDurations parses.
+func formatDurations(durations []metav1.Duration) string {
+	s := make([]string, len(durations))
+	for i, d := range durations {
+		s[i] = d.Duration.String()
+	}
+	return strings.Join(s, ",")
+}
+
+// parseDurations parses a comma separated list of durations.
+func parseDurations(s string) ([]metav1.Duration, error) {
+	var durations []metav1.Duration
+	for _, e := range splitList(s) {
+		d, err := time.ParseDuration(e)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q: %v", e, err)
+		}
+		durations = append(durations, metav1.Duration{Duration: d})
+	}
+	return durations, nil
+}
+
+// parseIntegrations parses a comma separated list of integrations of the form <name>=<id>.
+func parseIntegrations(s string) (map[string]int, error) {
+	integrations := make(map[string]int)
+	for _, e := range splitList(s) {
+		parts := strings.SplitN(e, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid integration %q, expected <name>=<id>", e)
+		}
+		id, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID of integration %q: %v", parts[0], err)
+		}
+		integrations[parts[0]] = id
+	}
+	return integrations, nil
+}
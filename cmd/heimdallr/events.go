@@ -0,0 +1,31 @@
+package main
+
+import (
+	heimdallrv1 "github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	"github.com/jeromefroe/heimdallr/pkg/client/clientset/versioned/scheme"
+
+	"go.uber.org/zap"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// reasonRecreated is the reason of the event recorded when a check is recreated.
+const reasonRecreated = "Recreated"
+
+// newEventRecorder returns a recorder of events about heimdallr's resources.
+func newEventRecorder(kubeCli kubernetes.Interface, logger *zap.Logger) record.EventRecorder {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartLogging(logger.Sugar().Debugf)
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeCli.CoreV1().Events("")})
+	return broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: "heimdallr"})
+}
+
+// recreatedEvents returns a handler which records an event on checks which are recreated after
+// being deleted from Pingdom outside of heimdallr.
+func recreatedEvents(recorder record.EventRecorder) func(heimdallrv1.HTTPCheck) {
+	return func(chk heimdallrv1.HTTPCheck) {
+		recorder.Event(&chk, v1.EventTypeWarning, reasonRecreated, "check was deleted from Pingdom and has been recreated")
+	}
+}
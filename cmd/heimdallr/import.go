@@ -0,0 +1,167 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	heimdallrv1 "github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	"github.com/jeromefroe/heimdallr/pkg/pingdom"
+
+	"github.com/ghodss/yaml"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// maxNameLength is the maximum length of the name of a HTTPCheck.
+const maxNameLength = validation.DNS1123SubdomainMaxLength
+
+// manifest is the subset of a HTTPCheck that is written out for imported checks.
+type manifest struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Spec heimdallrv1.HTTPCheckSpec `json:"spec"`
+}
+
+// runImport writes a HTTPCheck manifest for every Pingdom HTTP check which isn't managed by
+// heimdallr, optionally adopting the checks so heimdallr manages them from then on.
+func runImport(args []string, logger *zap.Logger) {
+	var (
+		fs    = flag.NewFlagSet("import", flag.ExitOnError)
+		creds = credentialFlags(fs)
+
+		namespace = fs.String("namespace", "default", "Namespace of checks whose name doesn't start with one")
+		outputDir = fs.String("output-dir", "", "Directory to write a manifest per check to (default stdout)")
+		adopt     = fs.Bool("adopt", false, "Update the imported checks so they are managed by heimdallr")
+		cluster   = fs.String("cluster", "", "Identifier of the cluster adopted checks are managed by, should match the controller's")
+	)
+	if err := fs.Parse(args); err != nil {
+		logger.Fatal("unable to parse flags", zap.Error(err))
+	}
+
+	pc, err := creds.newClient(logger, pingdom.WithCluster(*cluster))
+	if err != nil {
+		logger.Fatal("unable to create pingdom client", zap.Error(err))
+	}
+
+	checks, err := pc.ListUnmanagedHTTPChecks()
+	if err != nil {
+		logger.Fatal("unable to list pingdom checks", zap.Error(err))
+	}
+
+	taken := make(map[string]bool)
+	for i, chk := range checks {
+		ns, name := mapName(chk.Name, *namespace)
+		if unique := uniqueName(taken, ns, name); unique != name {
+			logger.Warn(
+				"renamed check whose name is already taken",
+				zap.String("name", chk.Name),
+				zap.String("namespace", ns),
+				zap.String("taken", name),
+				zap.String("renamed", unique),
+			)
+			name = unique
+		}
+
+		var m manifest
+		m.APIVersion = heimdallrv1.SchemeGroupVersion.String()
+		m.Kind = heimdallrv1.ResourceKind
+		m.Metadata.Name = name
+		m.Metadata.Namespace = ns
+		m.Spec = chk.Spec
+
+		b, err := yaml.Marshal(m)
+		if err != nil {
+			logger.Fatal("unable to marshal check", zap.String("name", chk.Name), zap.Error(err))
+		}
+
+		if *outputDir == "" {
+			if i > 0 {
+				fmt.Println("---")
+			}
+			fmt.Print(string(b))
+		} else {
+			// Neither namespaces nor names contain underscores, so the paths are unique.
+			path := filepath.Join(*outputDir, fmt.Sprintf("%s_%s.yaml", ns, name))
+			if err := ioutil.WriteFile(path, b, 0644); err != nil {
+				logger.Fatal("unable to write manifest", zap.String("path", path), zap.Error(err))
+			}
+		}
+
+		if *adopt {
+			check := heimdallrv1.HTTPCheck{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: ns,
+				},
+				Spec: chk.Spec,
+			}
+			if err := pc.AdoptHTTPCheck(chk.ID, check); err != nil {
+				logger.Fatal("unable to adopt check", zap.String("name", chk.Name), zap.Error(err))
+			}
+			logger.Info("adopted check", zap.String("name", chk.Name))
+		}
+	}
+
+	logger.Info("imported checks", zap.Int("count", len(checks)))
+}
+
+// mapName maps the name of a Pingdom check to the namespace and name of a HTTPCheck. Checks
+// named "<namespace>/<name>", the format heimdallr uses, keep their namespace while all other
+// checks are placed in the default namespace. The name is always a valid resource name.
+func mapName(pingdomName, defaultNamespace string) (string, string) {
+	ns, name := defaultNamespace, pingdomName
+	if parts := strings.SplitN(pingdomName, "/", 2); len(parts) == 2 {
+		if len(validation.IsDNS1123Label(parts[0])) == 0 {
+			ns, name = parts[0], parts[1]
+		}
+	}
+
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '-'
+		}
+	}, name)
+	name = strings.Trim(name, "-.")
+
+	if len(validation.IsDNS1123Subdomain(name)) > 0 {
+		// Each part of a name separated by dots must start and end with a letter or digit.
+		name = strings.Trim(strings.Replace(name, ".", "-", -1), "-")
+	}
+	name = truncateName(name, maxNameLength)
+	if name == "" {
+		name = "check"
+	}
+	return ns, name
+}
+
+// uniqueName returns the name, with a numeric suffix if needed, such that no other check in the
+// namespace has the same name, and records it as taken.
+func uniqueName(taken map[string]bool, ns, name string) string {
+	unique := name
+	for i := 2; taken[ns+"/"+unique]; i++ {
+		suffix := fmt.Sprintf("-%d", i)
+		unique = truncateName(name, maxNameLength-len(suffix)) + suffix
+	}
+	taken[ns+"/"+unique] = true
+	return unique
+}
+
+// truncateName shortens a name to at most max characters without leaving a trailing separator.
+func truncateName(name string, max int) string {
+	if len(name) <= max {
+		return name
+	}
+	return strings.TrimRight(name[:max], "-.")
+}
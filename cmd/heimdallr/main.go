@@ -2,60 +2,318 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"time"
 
 	heimdallrv1 "github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
 	clientset "github.com/jeromefroe/heimdallr/pkg/client/clientset/versioned"
+	"github.com/jeromefroe/heimdallr/pkg/config"
 	"github.com/jeromefroe/heimdallr/pkg/controller"
+	"github.com/jeromefroe/heimdallr/pkg/discovery"
 	"github.com/jeromefroe/heimdallr/pkg/pingdom"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/fields"
+	extv1beta1 "k8s.io/api/extensions/v1beta1"
+	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 )
 
-func main() {
-	var (
-		username = flag.String("username", os.Getenv("PINGDOM_USERNAME"), "Pingdom Username")
-		password = flag.String("password", os.Getenv("PINGDOM_PASSWORD"), "Pingdom Password")
-		appkey   = flag.String("appkey", os.Getenv("PINGDOM_APPKEY"), "Pingdom Application Key")
-	)
-	flag.Parse()
+// credentials holds the flags used to configure the Pingdom client.
+type credentials struct {
+	username   *string
+	password   *string
+	appkey     *string
+	token      *string
+	contactIDs *string
+}
 
+func credentialFlags(fs *flag.FlagSet) credentials {
+	return credentials{
+		username:   fs.String("username", os.Getenv("PINGDOM_USERNAME"), "Pingdom Username"),
+		password:   fs.String("password", os.Getenv("PINGDOM_PASSWORD"), "Pingdom Password"),
+		appkey:     fs.String("appkey", os.Getenv("PINGDOM_APPKEY"), "Pingdom Application Key"),
+		token:      fs.String("token", os.Getenv("PINGDOM_TOKEN"), "Pingdom API Token, used instead of the username, password and application key"),
+		contactIDs: fs.String("contact-ids", os.Getenv("PINGDOM_CONTACT_IDS"), "Comma separated IDs of the Pingdom users alerted by checks"),
+	}
+}
+
+func (c credentials) credentials() pingdom.Credentials {
+	return pingdom.Credentials{
+		Username: *c.username,
+		Password: *c.password,
+		AppKey:   *c.appkey,
+		Token:    *c.token,
+	}
+}
+
+func (c credentials) newClient(logger *zap.Logger, opts ...pingdom.Option) (*pingdom.Client, error) {
+	ids, err := parseIDs(*c.contactIDs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contact IDs: %v", err)
+	}
+	if len(ids) > 0 {
+		opts = append(opts, pingdom.WithContactIDs(ids...))
+	}
+	return pingdom.NewFromCredentials(c.credentials(), logger, opts...)
+}
+
+// clientOptions returns the options of the Pingdom clients created for the configuration, which
+// the controller and the plan command share so that they agree on how checks look in Pingdom.
+func clientOptions(cfg config.Config) ([]pingdom.Option, error) {
+	opts := []pingdom.Option{
+		pingdom.WithDryRun(cfg.Features.DryRun),
+		pingdom.WithDefaults(heimdallrv1.HTTPCheckSpec{
+			IntervalMinutes:    cfg.Defaults.IntervalMinutes,
+			TriggerThreshold:   cfg.Defaults.TriggerThreshold,
+			RetriggerThreshold: cfg.Defaults.RetriggerThreshold,
+			IntegrationIDs:     cfg.Defaults.IntegrationIDs,
+		}),
+		pingdom.WithUptimeRefresh(cfg.Uptime.RefreshInterval.Duration),
+		pingdom.WithRateLimit(cfg.RateLimit.RequestsPerSecond, cfg.RateLimit.Burst),
+	}
+	if cfg.Cluster != "" {
+		opts = append(opts, pingdom.WithCluster(cfg.Cluster))
+	}
+	if len(cfg.Integrations) > 0 {
+		opts = append(opts, pingdom.WithIntegrations(cfg.Integrations))
+	}
+	if cfg.LabelTags.Prefix != "" || len(cfg.LabelTags.Keys) > 0 {
+		opts = append(opts, pingdom.WithLabelTags(cfg.LabelTags.Prefix, cfg.LabelTags.Keys...))
+	}
+	if len(cfg.Credentials.ContactIDs) > 0 {
+		opts = append(opts, pingdom.WithContactIDs(cfg.Credentials.ContactIDs...))
+	}
+	if cfg.NameTemplate != "" {
+		tmpl, err := pingdom.ParseNameTemplate(cfg.NameTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name template: %v", err)
+		}
+		opts = append(opts, pingdom.WithNameTemplate(tmpl))
+	}
+	return opts, nil
+}
+
+// configCredentials returns the Pingdom credentials set in the configuration.
+func configCredentials(c config.Credentials) pingdom.Credentials {
+	return pingdom.Credentials{
+		Username: c.Username,
+		Password: c.Password,
+		AppKey:   c.AppKey,
+		Token:    c.Token,
+	}
+}
+
+func main() {
 	logger, err := zap.NewProduction()
 	if err != nil {
 		log.Fatalf("failed to create logger: %v", err)
 	}
 
-	cfg, err := rest.InClusterConfig()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			runImport(os.Args[2:], logger)
+			return
+		case "plan":
+			runPlan(os.Args[2:], logger)
+			return
+		}
+	}
+
+	runController(logger)
+}
+
+func runController(logger *zap.Logger) {
+	cfg, err := loadConfig(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		logger.Fatal("invalid configuration", zap.Error(err))
+	}
+
+	opts, err := clientOptions(cfg)
+	if err != nil {
+		logger.Fatal("invalid configuration", zap.Error(err))
+	}
+
+	restCfg, err := rest.InClusterConfig()
 	if err != nil {
 		logger.Fatal("unable to create in cluster config", zap.Error(err))
 	}
 
-	cli, err := clientset.NewForConfig(cfg)
+	cli, err := clientset.NewForConfig(restCfg)
 	if err != nil {
 		logger.Fatal("unable to create heimdallr client", zap.Error(err))
 	}
 
-	lw := cache.NewListWatchFromClient(
-		cli.HeimdallrV1alpha1().RESTClient(), heimdallrv1.ResourcePlural, v1.NamespaceAll, fields.Everything(),
+	kubeCli, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		logger.Fatal("unable to create kubernetes client", zap.Error(err))
+	}
+
+	// Checks deleted from Pingdom outside of heimdallr are recreated, which is recorded as an
+	// event on the check.
+	opts = append(opts, pingdom.WithRecreateHandler(recreatedEvents(newEventRecorder(kubeCli, logger))))
+
+	pc := pingdom.NewReloadingClient(func(c pingdom.Credentials) (*pingdom.Client, error) {
+		return pingdom.NewFromCredentials(c, logger, opts...)
+	}, logger)
+
+	http.Handle("/metrics", promhttp.Handler())
+	http.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if err := pc.Ready(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = fmt.Fprintln(w, err)
+			return
+		}
+		_, _ = fmt.Fprintln(w, "ok")
+	})
+	go func() {
+		logger.Info("serving metrics", zap.String("address", cfg.MetricsAddress))
+		if err := http.ListenAndServe(cfg.MetricsAddress, nil); err != nil {
+			logger.Fatal("unable to serve metrics", zap.Error(err))
+		}
+	}()
+
+	if dir := cfg.Credentials.Dir; dir == "" {
+		if err := pc.Reload(configCredentials(cfg.Credentials)); err != nil {
+			logger.Fatal("unable to create pingdom client", zap.Error(err))
+		}
+	} else {
+		load := func() (pingdom.Credentials, error) { return readCredentialsDir(dir) }
+		interval := cfg.Credentials.ReloadInterval.Duration
+
+		// Wait, unready, for valid credentials rather than failing so that they can be fixed
+		// without a restart.
+		if c, err := load(); err != nil {
+			logger.Error("unable to load pingdom credentials", zap.Error(err))
+		} else if err := pc.Reload(c); err != nil {
+			logger.Error("unable to create pingdom client", zap.Error(err))
+		}
+		go pc.Watch(nil, interval, load)
+
+		for pc.Ready() != nil {
+			time.Sleep(interval)
+		}
+	}
+	logger.Info("successfully created Pingdom client", zap.Bool("dryRun", cfg.Features.DryRun))
+
+	var (
+		stop   = make(chan struct{})
+		sc     = newScope(cfg.Namespaces, cfg.Selector, cfg.ResyncPeriod.Duration)
+		checks = checkLister{checks: cli.HeimdallrV1alpha1(), selector: cfg.Selector}
 	)
-	sw := cache.NewSharedInformer(lw, new(heimdallrv1.HTTPCheck), time.Duration(0)) // resync timer disabled
 
-	pc, err := pingdom.New(*username, *password, *appkey, logger)
-	if err != nil {
-		logger.Fatal("unable to create pingdom client", zap.Error(err))
+	ctrlOpts := []controller.Option{
+		controller.WithAccounts(
+			secretCredentials{secrets: kubeCli.CoreV1()},
+			func(c pingdom.Credentials) (*pingdom.Client, error) {
+				return pingdom.NewFromCredentials(c, logger, opts...)
+			},
+		),
+		controller.WithStatusUpdater(statusUpdater{checks: cli.HeimdallrV1alpha1()}),
+		controller.WithDryRun(cfg.Features.DryRun),
+	}
+	rollouts := new(deploymentRollouts)
+	if cfg.Features.RolloutPause {
+		ctrlOpts = append(ctrlOpts, controller.WithRollouts(rollouts, checks))
 	}
-	logger.Info("successfully created Pingdom client")
+	if interval := cfg.ReconcileInterval.Duration; interval > 0 {
+		ctrlOpts = append(ctrlOpts, controller.WithReconciliation(checks, sc.namespaces, interval))
+		if cfg.Features.DeleteOrphans {
+			ctrlOpts = append(ctrlOpts, controller.WithOrphanDeletion(pc, sc.selector))
+		}
+	}
+
+	// Maintenance windows are updated again once the checks they select are created.
+	var windows *controller.MaintenanceHandler
+	if cfg.Features.Maintenance {
+		windows = controller.NewMaintenanceHandler(pc, checks, logger)
+		ctrlOpts = append(ctrlOpts, controller.WithMaintenance(windows))
+	}
+	ctrl := controller.New(pc, logger, ctrlOpts...)
+
+	if cfg.Features.IngressDiscovery {
+		logger.Info("starting ingress discovery")
+		sc.watch(
+			kubeCli.ExtensionsV1beta1().RESTClient(), "ingresses", new(extv1beta1.Ingress),
+			discovery.NewIngressHandler(cli.HeimdallrV1alpha1(), logger), stop,
+		)
+	}
+
+	if cfg.Features.ServiceDiscovery {
+		logger.Info("starting service discovery")
+		sc.watch(
+			kubeCli.CoreV1().RESTClient(), "services", new(v1.Service),
+			discovery.NewServiceHandler(cli.HeimdallrV1alpha1(), logger), stop,
+		)
+	}
+
+	if cfg.Features.Alerting {
+		logger.Info("starting contact and team management")
+		teams := controller.NewTeamHandler(pc, logger)
+		contacts := controller.NewContactHandler(pc, teams, logger)
+		go teams.Run(stop)
+		go contacts.Run(stop)
 
-	ctrl := controller.New(pc, logger)
-	sw.AddEventHandler(ctrl)
+		sc.watch(
+			cli.HeimdallrV1alpha1().RESTClient(), heimdallrv1.ContactResourcePlural, new(heimdallrv1.PingdomContact),
+			contacts, stop,
+		)
+		sc.watch(
+			cli.HeimdallrV1alpha1().RESTClient(), heimdallrv1.TeamResourcePlural, new(heimdallrv1.PingdomTeam),
+			teams, stop,
+		)
+	}
+
+	if cfg.Features.Maintenance {
+		logger.Info("starting maintenance window management")
+		go windows.Run(stop)
+		sc.watch(
+			cli.HeimdallrV1alpha1().RESTClient(), heimdallrv1.MaintenanceWindowResourcePlural, new(heimdallrv1.MaintenanceWindow),
+			windows, stop,
+		)
+	}
+
+	if cfg.Features.RolloutPause {
+		// Deployments are watched regardless of the selector, which applies to checks, and are
+		// listed before any checks are processed so that their rollouts are known.
+		logger.Info("starting rollout watch")
+		rollouts.deployments = newScope(cfg.Namespaces, "", cfg.ResyncPeriod.Duration).watch(
+			kubeCli.AppsV1().RESTClient(), "deployments", new(appsv1.Deployment),
+			rolloutHandler{ctrl: ctrl}, stop,
+		)
+		if !cache.WaitForCacheSync(stop, rollouts.deployments.hasSynced) {
+			logger.Fatal("unable to list deployments")
+		}
+	}
+
+	if interval := cfg.StatusInterval.Duration; interval > 0 {
+		logger.Info("starting status poller", zap.Duration("interval", interval))
+		windows := make([]time.Duration, len(cfg.Uptime.Windows))
+		for i, w := range cfg.Uptime.Windows {
+			windows[i] = w.Duration
+		}
+		poller := controller.NewStatusPoller(
+			pc, checks, statusUpdater{checks: cli.HeimdallrV1alpha1()}, logger,
+			controller.WithUptimeWindows(windows...),
+		)
+		go poller.Run(sc.namespaces, interval, stop)
+	}
 
-	logger.Info("starting controller")
-	sw.Run(nil)
+	logger.Info(
+		"starting controller",
+		zap.Strings("namespaces", sc.namespaces),
+		zap.String("selector", sc.selector),
+		zap.Int("workers", cfg.Workers),
+		zap.Duration("reconcileInterval", cfg.ReconcileInterval.Duration),
+	)
+	sc.watch(cli.HeimdallrV1alpha1().RESTClient(), heimdallrv1.ResourcePlural, new(heimdallrv1.HTTPCheck), ctrl, stop)
+	ctrl.Run(cfg.Workers, stop)
 }
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	heimdallrv1 "github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	clientset "github.com/jeromefroe/heimdallr/pkg/client/clientset/versioned"
+	"github.com/jeromefroe/heimdallr/pkg/config"
+	"github.com/jeromefroe/heimdallr/pkg/pingdom"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// driftExitCode is the exit code of the plan command when Pingdom doesn't match the checks.
+const driftExitCode = 2
+
+// fileFlags is a flag which can be specified multiple times.
+type fileFlags []string
+
+func (f *fileFlags) String() string     { return strings.Join(*f, ",") }
+func (f *fileFlags) Set(v string) error { *f = append(*f, v); return nil }
+
+// runPlan prints the changes heimdallr would make to Pingdom for the checks defined in the
+// given files, or in the cluster if no files are given, and exits with a non-zero exit code
+// if there are any. It takes the same configuration as the controller, so that it plans the
+// checks the controller would create.
+func runPlan(args []string, logger *zap.Logger) {
+	var (
+		fs    = flag.NewFlagSet("plan", flag.ExitOnError)
+		files fileFlags
+
+		kubeconfig = fs.String("kubeconfig", os.Getenv("KUBECONFIG"), "Path to a kubeconfig, used if no files are given")
+	)
+	fs.Var(&files, "f", "File containing HTTPCheck manifests, may be repeated")
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		logger.Fatal("invalid configuration", zap.Error(err))
+	}
+
+	var (
+		checks []heimdallrv1.HTTPCheck
+		scoped = len(cfg.Namespaces) > 0 || cfg.Selector != ""
+	)
+	if len(files) > 0 {
+		checks, err = readCheckFiles(files)
+	} else {
+		checks, err = readClusterChecks(*kubeconfig, cfg)
+	}
+	if err != nil {
+		logger.Fatal("unable to read checks", zap.Error(err))
+	}
+	checks = defaultAccountChecks(checks, logger)
+
+	opts, err := clientOptions(cfg)
+	if err != nil {
+		logger.Fatal("invalid configuration", zap.Error(err))
+	}
+
+	creds := configCredentials(cfg.Credentials)
+	if dir := cfg.Credentials.Dir; dir != "" {
+		if creds, err = readCredentialsDir(dir); err != nil {
+			logger.Fatal("unable to load pingdom credentials", zap.Error(err))
+		}
+	}
+
+	pc, err := pingdom.NewFromCredentials(creds, logger, opts...)
+	if err != nil {
+		logger.Fatal("unable to create pingdom client", zap.Error(err))
+	}
+
+	changes, err := pc.Plan(checks)
+	if err != nil {
+		logger.Fatal("unable to plan changes", zap.Error(err))
+	}
+	if len(files) == 0 && scoped {
+		// Like the controller, a scoped plan can't tell the checks of other namespaces or
+		// selectors apart from deleted ones.
+		changes = withoutDeletions(changes)
+	}
+	printPlan(os.Stdout, changes)
+
+	if len(changes) > 0 {
+		os.Exit(driftExitCode)
+	}
+}
+
+// defaultAccountChecks filters out checks which belong to another Pingdom account than the
+// one the command was given credentials for.
+func defaultAccountChecks(checks []heimdallrv1.HTTPCheck, logger *zap.Logger) []heimdallrv1.HTTPCheck {
+	filtered := checks[:0]
+	for _, check := range checks {
+		if check.Spec.CredentialsRef != nil {
+			logger.Info("skipping check which references another account", zap.String("name", check.Name))
+			continue
+		}
+		filtered = append(filtered, check)
+	}
+	return filtered
+}
+
+func readCheckFiles(files []string) ([]heimdallrv1.HTTPCheck, error) {
+	var checks []heimdallrv1.HTTPCheck
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+
+		dec := yaml.NewYAMLOrJSONDecoder(f, 4096)
+		for {
+			var check heimdallrv1.HTTPCheck
+			if err := dec.Decode(&check); err == io.EOF {
+				break
+			} else if err != nil {
+				_ = f.Close()
+				return nil, fmt.Errorf("failed to decode %v: %v", file, err)
+			}
+
+			if check.Kind == heimdallrv1.ResourceKind {
+				checks = append(checks, check)
+			}
+		}
+
+		if err := f.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return checks, nil
+}
+
+// readClusterChecks lists the checks in the namespaces and matching the selector the controller
+// watches with the given configuration.
+func readClusterChecks(kubeconfig string, cfg config.Config) ([]heimdallrv1.HTTPCheck, error) {
+	restCfg, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := clientset.NewForConfig(restCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		checks []heimdallrv1.HTTPCheck
+		sc     = newScope(cfg.Namespaces, cfg.Selector, 0)
+		lister = checkLister{checks: cli.HeimdallrV1alpha1(), selector: sc.selector}
+	)
+	for _, ns := range sc.namespaces {
+		list, err := lister.ListChecks(ns, "")
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, list...)
+	}
+	return checks, nil
+}
+
+// withoutDeletions filters out the planned deletions of checks.
+func withoutDeletions(changes []pingdom.Change) []pingdom.Change {
+	filtered := changes[:0]
+	for _, change := range changes {
+		if change.Action != pingdom.ActionDelete {
+			filtered = append(filtered, change)
+		}
+	}
+	return filtered
+}
+
+func printPlan(w io.Writer, changes []pingdom.Change) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No changes, Pingdom matches the checks.")
+		return
+	}
+
+	symbols := map[pingdom.Action]string{
+		pingdom.ActionCreate: "+",
+		pingdom.ActionUpdate: "~",
+		pingdom.ActionDelete: "-",
+	}
+
+	counts := make(map[pingdom.Action]int)
+	for _, change := range changes {
+		counts[change.Action]++
+
+		fmt.Fprintf(w, "%s %s %s\n", symbols[change.Action], change.Action, change.Name)
+		for _, diff := range change.Diffs {
+			fmt.Fprintf(w, "    %s: %q => %q\n", diff.Field, diff.Current, diff.Desired)
+		}
+	}
+
+	fmt.Fprintf(
+		w, "\nPlan: %d to create, %d to update, %d to delete.\n",
+		counts[pingdom.ActionCreate], counts[pingdom.ActionUpdate], counts[pingdom.ActionDelete],
+	)
+}
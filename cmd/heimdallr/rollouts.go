@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jeromefroe/heimdallr/pkg/controller"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/cache"
+)
+
+// deploymentRollouts gets whether Deployments are rolling out from the Deployments watched by an
+// informer. The store is set once the informer is started, which needs the controller, but before
+// the controller processes any checks.
+type deploymentRollouts struct {
+	deployments store
+}
+
+func (r *deploymentRollouts) RolloutInProgress(namespace, name string) (bool, error) {
+	obj, exists, err := r.deployments.get(namespace, name)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, errors.NewNotFound(appsv1.Resource("deployments"), name)
+	}
+	d, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return false, fmt.Errorf("unexpected type %T for deployment %v/%v", obj, namespace, name)
+	}
+	return rollingOut(d), nil
+}
+
+// rolloutHandler notifies the controller when a rollout of a Deployment starts or completes.
+type rolloutHandler struct {
+	ctrl *controller.Controller
+}
+
+func (h rolloutHandler) OnAdd(obj interface{}) {}
+
+func (h rolloutHandler) OnUpdate(oldObj, newObj interface{}) {
+	oldD, ok := oldObj.(*appsv1.Deployment)
+	if !ok {
+		return
+	}
+	newD, ok := newObj.(*appsv1.Deployment)
+	if !ok {
+		return
+	}
+
+	if rollingOut(oldD) != rollingOut(newD) {
+		h.ctrl.RolloutChanged(newD.Namespace, newD.Name)
+	}
+}
+
+func (h rolloutHandler) OnDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if d, ok := obj.(*appsv1.Deployment); ok {
+		h.ctrl.RolloutChanged(d.Namespace, d.Name)
+	}
+}
+
+// rollingOut returns whether a Deployment is rolling out, using the same criteria as
+// kubectl rollout status.
+func rollingOut(d *appsv1.Deployment) bool {
+	if d.Generation > d.Status.ObservedGeneration {
+		return true
+	}
+
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return d.Status.UpdatedReplicas < replicas ||
+		d.Status.Replicas > d.Status.UpdatedReplicas ||
+		d.Status.AvailableReplicas < d.Status.UpdatedReplicas
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// scope restricts the resources watched by heimdallr to a set of namespaces and a label selector.
+type scope struct {
+	namespaces []string
+	selector   string
+	resync     time.Duration
+}
+
+// newScope creates a scope for the given namespaces, or all namespaces if none are given. The
+// selector must already have been validated.
+func newScope(namespaces []string, selector string, resync time.Duration) scope {
+	if len(namespaces) == 0 {
+		namespaces = []string{v1.NamespaceAll}
+	}
+	return scope{
+		namespaces: namespaces,
+		selector:   selector,
+		resync:     resync,
+	}
+}
+
+// watch starts an informer for the given resource in each namespace of the scope, passing events
+// to the handler. The informers run until stop is closed, and the resources they watch can be
+// read from the returned store.
+func (s scope) watch(
+	c cache.Getter,
+	resource string,
+	objType runtime.Object,
+	handler cache.ResourceEventHandler,
+	stop <-chan struct{},
+) store {
+	st := store{informers: make(map[string]cache.SharedInformer, len(s.namespaces))}
+	for _, ns := range s.namespaces {
+		lw := cache.NewFilteredListWatchFromClient(c, resource, ns, func(options *metav1.ListOptions) {
+			options.LabelSelector = s.selector
+		})
+		informer := cache.NewSharedInformer(lw, objType, s.resync)
+		informer.AddEventHandler(handler)
+		go informer.Run(stop)
+		st.informers[ns] = informer
+	}
+	return st
+}
+
+// store reads the resources watched by the informers of a scope.
+type store struct {
+	informers map[string]cache.SharedInformer
+}
+
+// get returns the resource with the given namespace and name, and whether it exists.
+func (s store) get(namespace, name string) (interface{}, bool, error) {
+	informer, ok := s.informers[namespace]
+	if !ok {
+		if informer, ok = s.informers[v1.NamespaceAll]; !ok {
+			return nil, false, nil
+		}
+	}
+	return informer.GetStore().GetByKey(namespace + "/" + name)
+}
+
+// hasSynced returns whether every informer has listed its resources.
+func (s store) hasSynced() bool {
+	for _, informer := range s.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeromefroe/heimdallr/pkg/pingdom"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// secretCredentials reads Pingdom credentials from Secrets, using the same keys as the
+// environment variables of the controller. Only the Secrets referenced by checks are read, when
+// they're needed, so that heimdallr doesn't cache every Secret it can access.
+type secretCredentials struct {
+	secrets corev1.SecretsGetter
+}
+
+func (s secretCredentials) GetCredentials(namespace, name string) (pingdom.Credentials, string, error) {
+	secret, err := s.secrets.Secrets(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return pingdom.Credentials{}, "", err
+	}
+
+	creds, err := credentialsFromData(secret.Data)
+	if err != nil {
+		return pingdom.Credentials{}, "", err
+	}
+	return creds, secret.ResourceVersion, nil
+}
+
+// readCredentialsDir reads Pingdom credentials from a directory containing a file for each key,
+// such as a mounted Secret.
+func readCredentialsDir(dir string) (pingdom.Credentials, error) {
+	data := make(map[string][]byte)
+	for _, key := range []string{"PINGDOM_USERNAME", "PINGDOM_PASSWORD", "PINGDOM_APPKEY", "PINGDOM_TOKEN"} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, key))
+		if err != nil && !os.IsNotExist(err) {
+			return pingdom.Credentials{}, err
+		}
+		data[key] = []byte(strings.TrimSpace(string(b)))
+	}
+	return credentialsFromData(data)
+}
+
+func credentialsFromData(data map[string][]byte) (pingdom.Credentials, error) {
+	creds := pingdom.Credentials{
+		Username: string(data["PINGDOM_USERNAME"]),
+		Password: string(data["PINGDOM_PASSWORD"]),
+		AppKey:   string(data["PINGDOM_APPKEY"]),
+		Token:    string(data["PINGDOM_TOKEN"]),
+	}
+	if creds.Token == "" && (creds.Username == "" || creds.Password == "" || creds.AppKey == "") {
+		return pingdom.Credentials{}, fmt.Errorf(
+			"credentials must contain either PINGDOM_TOKEN or PINGDOM_USERNAME, PINGDOM_PASSWORD and PINGDOM_APPKEY",
+		)
+	}
+	return creds, nil
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	heimdallrv1 "github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	heimdallrclient "github.com/jeromefroe/heimdallr/pkg/client/clientset/versioned/typed/heimdallr/v1alpha1"
+)
+
+// statusUpdater persists the status of checks with the status subresource.
+type statusUpdater struct {
+	checks heimdallrclient.HTTPChecksGetter
+}
+
+func (s statusUpdater) UpdateStatus(chk *heimdallrv1.HTTPCheck) error {
+	_, err := s.checks.HTTPChecks(chk.Namespace).UpdateStatus(chk)
+	return err
+}
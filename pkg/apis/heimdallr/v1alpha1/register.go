@@ -34,6 +34,24 @@ const (
 
 	// ResourcePlural is the CRD Kind pluralized.
 	ResourcePlural = "httpchecks"
+
+	// MaintenanceWindowResourceKind is the Kind of the MaintenanceWindow CRD.
+	MaintenanceWindowResourceKind = "MaintenanceWindow"
+
+	// MaintenanceWindowResourcePlural is the Kind of the MaintenanceWindow CRD pluralized.
+	MaintenanceWindowResourcePlural = "maintenancewindows"
+
+	// ContactResourceKind is the Kind of the PingdomContact CRD.
+	ContactResourceKind = "PingdomContact"
+
+	// ContactResourcePlural is the Kind of the PingdomContact CRD pluralized.
+	ContactResourcePlural = "pingdomcontacts"
+
+	// TeamResourceKind is the Kind of the PingdomTeam CRD.
+	TeamResourceKind = "PingdomTeam"
+
+	// TeamResourcePlural is the Kind of the PingdomTeam CRD pluralized.
+	TeamResourcePlural = "pingdomteams"
 )
 
 var (
@@ -62,6 +80,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&HTTPCheck{},
 		&HTTPCheckList{},
+		&MaintenanceWindow{},
+		&MaintenanceWindowList{},
+		&PingdomContact{},
+		&PingdomContactList{},
+		&PingdomTeam{},
+		&PingdomTeamList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
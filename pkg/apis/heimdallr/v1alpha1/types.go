@@ -36,20 +36,114 @@ type HTTPCheck struct {
 	Status HTTPCheckStatus `json:"status"`
 }
 
+// CheckType is the protocol a check probes over.
+type CheckType string
+
+const (
+	// CheckTypeHTTP checks make a HTTP request to the URL of the check. It's the default.
+	CheckTypeHTTP CheckType = "http"
+
+	// CheckTypeTCP checks only open a TCP connection to the port of the check, for services
+	// which don't serve HTTP. They ignore the URL and enableTLS settings.
+	CheckTypeTCP CheckType = "tcp"
+)
+
 // HTTPCheckSpec is the spec for a HTTPCheck resource.
 type HTTPCheckSpec struct {
+	// Type is the protocol the check probes over, http if it's unset.
+	Type CheckType `json:"type,omitempty"`
+
 	Hostname           string `json:"hostname"`
+	URL                string `json:"url,omitempty"`
+	Port               int    `json:"port,omitempty"`
 	IntervalMinutes    int    `json:"intervalMinutes"`
 	TriggerThreshold   int    `json:"triggerThreshold"`
 	RetriggerThreshold int    `json:"retriggerThreshold"`
 	NotifyWhenBackup   bool   `json:"notifyWhenBackup"`
 	EnableTLS          bool   `json:"enableTLS"`
 	IntegrationIDs     []int  `json:"integrationIDs"`
+
+	// Integrations, Contacts and Teams are the names of the integrations, users and teams
+	// alerted by the check, resolved to their IDs in Pingdom.
+	Integrations []string `json:"integrations,omitempty"`
+	Contacts     []string `json:"contacts,omitempty"`
+	Teams        []string `json:"teams,omitempty"`
+
+	// NameTemplate is a Go template used to name the check in Pingdom instead of the template
+	// heimdallr was started with.
+	NameTemplate string `json:"nameTemplate,omitempty"`
+
+	// Tags are added to the check in Pingdom, along with any tags heimdallr derives from the
+	// labels of the check.
+	Tags []string `json:"tags,omitempty"`
+
+	// Paused pauses the check in Pingdom so that it neither runs nor alerts.
+	Paused bool `json:"paused,omitempty"`
+
+	// CredentialsRef references the Secret containing the credentials of the Pingdom account
+	// the check belongs to. The account heimdallr was started with is used if it is unset.
+	CredentialsRef *CredentialsReference `json:"credentialsRef,omitempty"`
+}
+
+// CredentialsReference references a Secret, in the namespace of the check, containing either the
+// PINGDOM_TOKEN or the PINGDOM_USERNAME, PINGDOM_PASSWORD and PINGDOM_APPKEY of a Pingdom account.
+type CredentialsReference struct {
+	SecretName string `json:"secretName"`
 }
 
 // HTTPCheckStatus is the status for a HTTPCheck resource.
 type HTTPCheckStatus struct {
-	State string `json:"status"`
+	State      string               `json:"status"`
+	Conditions []HTTPCheckCondition `json:"conditions,omitempty"`
+
+	// PingdomStatus is the status of the check in Pingdom, one of up, down, unconfirmed_down,
+	// unknown or paused.
+	PingdomStatus string       `json:"pingdomStatus,omitempty"`
+	LastTestTime  *metav1.Time `json:"lastTestTime,omitempty"`
+	LastErrorTime *metav1.Time `json:"lastErrorTime,omitempty"`
+
+	// LastResponseTime is the response time of the last test of the check in milliseconds.
+	LastResponseTime int64 `json:"lastResponseTime,omitempty"`
+
+	// Uptime is the percentage of recent windows for which the check was up.
+	Uptime []UptimeStatus `json:"uptime,omitempty"`
+}
+
+// UptimeStatus is the percentage of a window, such as 24h or 7d, for which a check was up.
+type UptimeStatus struct {
+	Window  string `json:"window"`
+	Percent string `json:"percent"`
+}
+
+// HTTPCheckConditionType is the type of a condition of a HTTPCheck.
+type HTTPCheckConditionType string
+
+const (
+	// HTTPCheckReady indicates whether the check is up to date in Pingdom.
+	HTTPCheckReady HTTPCheckConditionType = "Ready"
+
+	// HTTPCheckPaused indicates whether the check is paused in Pingdom, either by its spec or
+	// during the rollout of a Deployment.
+	HTTPCheckPaused HTTPCheckConditionType = "Paused"
+)
+
+// ConditionStatus is the status of a condition.
+type ConditionStatus string
+
+// The statuses of a condition.
+const (
+	ConditionTrue    ConditionStatus = "True"
+	ConditionFalse   ConditionStatus = "False"
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+// HTTPCheckCondition describes the state of a HTTPCheck at a certain point.
+type HTTPCheckCondition struct {
+	Type               HTTPCheckConditionType `json:"type"`
+	Status             ConditionStatus        `json:"status"`
+	LastTransitionTime metav1.Time            `json:"lastTransitionTime,omitempty"`
+	Reason             string                 `json:"reason,omitempty"`
+	Message            string                 `json:"message,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -61,3 +155,143 @@ type HTTPCheckList struct {
 
 	Items []HTTPCheck `json:"items"`
 }
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PingdomContact is a specification for a Pingdom contact, a user alerted by checks.
+type PingdomContact struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PingdomContactSpec `json:"spec"`
+}
+
+// PingdomContactSpec is the spec for a PingdomContact resource.
+type PingdomContactSpec struct {
+	// Name is the name of the contact in Pingdom, prefixed with its namespace, which checks and
+	// teams reference it by. The name of the resource is used if it is unset.
+	Name   string `json:"name,omitempty"`
+	Paused bool   `json:"paused,omitempty"`
+
+	// Adopt allows heimdallr to manage an existing Pingdom user of the same name which it didn't
+	// create, such as one created in the Pingdom UI. Unless it's set, heimdallr refuses to
+	// manage users it didn't create, so that it never changes or deletes them.
+	Adopt bool `json:"adopt,omitempty"`
+
+	Email []EmailTarget `json:"email,omitempty"`
+	SMS   []SMSTarget   `json:"sms,omitempty"`
+}
+
+// EmailTarget is an email address a contact is alerted at.
+type EmailTarget struct {
+	Address string `json:"address"`
+
+	// Severity is either high or low, defaulting to high.
+	Severity string `json:"severity,omitempty"`
+}
+
+// SMSTarget is a phone number a contact is alerted at by SMS.
+type SMSTarget struct {
+	CountryCode string `json:"countryCode"`
+	Number      string `json:"number"`
+	Provider    string `json:"provider,omitempty"`
+
+	// Severity is either high or low, defaulting to high.
+	Severity string `json:"severity,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PingdomContactList is a list of PingdomContact resources.
+type PingdomContactList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []PingdomContact `json:"items"`
+}
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PingdomTeam is a specification for a Pingdom team, a group of contacts alerted by checks.
+type PingdomTeam struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PingdomTeamSpec `json:"spec"`
+}
+
+// PingdomTeamSpec is the spec for a PingdomTeam resource.
+type PingdomTeamSpec struct {
+	// Name is the name of the team in Pingdom, prefixed with its namespace, which checks reference
+	// it by. The name of the resource is used if it is unset.
+	Name string `json:"name,omitempty"`
+
+	// Adopt allows heimdallr to manage an existing Pingdom team of the same name which it didn't
+	// create. Unless it's set, heimdallr refuses to manage teams it didn't create.
+	Adopt bool `json:"adopt,omitempty"`
+
+	// Members are the names of the contacts in the team.
+	Members []string `json:"members"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PingdomTeamList is a list of PingdomTeam resources.
+type PingdomTeamList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []PingdomTeam `json:"items"`
+}
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MaintenanceWindow is a specification for a Pingdom maintenance window, during which the checks
+// it selects don't alert.
+type MaintenanceWindow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MaintenanceWindowSpec `json:"spec"`
+}
+
+// MaintenanceWindowSpec is the spec for a MaintenanceWindow resource.
+type MaintenanceWindowSpec struct {
+	Description string      `json:"description,omitempty"`
+	Start       metav1.Time `json:"start"`
+	End         metav1.Time `json:"end"`
+
+	// Recurrence repeats the window, starting from its first occurrence between Start and End.
+	Recurrence *MaintenanceRecurrence `json:"recurrence,omitempty"`
+
+	// Selector selects the checks, in the namespace of the window, which are in maintenance.
+	Selector *metav1.LabelSelector `json:"selector"`
+}
+
+// MaintenanceRecurrence describes how a maintenance window repeats.
+type MaintenanceRecurrence struct {
+	// Type is either day, week or month.
+	Type string `json:"type"`
+
+	// RepeatEvery is the number of days, weeks or months between occurrences, defaulting to 1.
+	RepeatEvery int `json:"repeatEvery,omitempty"`
+
+	// Until is when the window stops repeating.
+	Until metav1.Time `json:"until"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MaintenanceWindowList is a list of MaintenanceWindow resources.
+type MaintenanceWindowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []MaintenanceWindow `json:"items"`
+}
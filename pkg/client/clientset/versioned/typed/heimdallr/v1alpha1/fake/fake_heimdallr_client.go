@@ -36,6 +36,18 @@ func (c *FakeHeimdallrV1alpha1) HTTPChecks(namespace string) v1alpha1.HTTPCheckI
 	return &FakeHTTPChecks{c, namespace}
 }
 
+func (c *FakeHeimdallrV1alpha1) MaintenanceWindows(namespace string) v1alpha1.MaintenanceWindowInterface {
+	return &FakeMaintenanceWindows{c, namespace}
+}
+
+func (c *FakeHeimdallrV1alpha1) PingdomContacts(namespace string) v1alpha1.PingdomContactInterface {
+	return &FakePingdomContacts{c, namespace}
+}
+
+func (c *FakeHeimdallrV1alpha1) PingdomTeams(namespace string) v1alpha1.PingdomTeamInterface {
+	return &FakePingdomTeams{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeHeimdallrV1alpha1) RESTClient() rest.Interface {
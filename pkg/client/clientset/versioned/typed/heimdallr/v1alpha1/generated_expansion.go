@@ -23,3 +23,9 @@
 package v1alpha1
 
 type HTTPCheckExpansion interface{}
+
+type MaintenanceWindowExpansion interface{}
+
+type PingdomContactExpansion interface{}
+
+type PingdomTeamExpansion interface{}
@@ -32,6 +32,9 @@ import (
 type HeimdallrV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	HTTPChecksGetter
+	MaintenanceWindowsGetter
+	PingdomContactsGetter
+	PingdomTeamsGetter
 }
 
 // HeimdallrV1alpha1Client is used to interact with features provided by the heimdallr.froe.io group.
@@ -43,6 +46,18 @@ func (c *HeimdallrV1alpha1Client) HTTPChecks(namespace string) HTTPCheckInterfac
 	return newHTTPChecks(c, namespace)
 }
 
+func (c *HeimdallrV1alpha1Client) MaintenanceWindows(namespace string) MaintenanceWindowInterface {
+	return newMaintenanceWindows(c, namespace)
+}
+
+func (c *HeimdallrV1alpha1Client) PingdomContacts(namespace string) PingdomContactInterface {
+	return newPingdomContacts(c, namespace)
+}
+
+func (c *HeimdallrV1alpha1Client) PingdomTeams(namespace string) PingdomTeamInterface {
+	return newPingdomTeams(c, namespace)
+}
+
 // NewForConfig creates a new HeimdallrV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*HeimdallrV1alpha1Client, error) {
 	config := *c
@@ -59,6 +59,12 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=heimdallr.froe.io, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("httpchecks"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Heimdallr().V1alpha1().HTTPChecks().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("maintenancewindows"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Heimdallr().V1alpha1().MaintenanceWindows().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("pingdomcontacts"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Heimdallr().V1alpha1().PingdomContacts().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("pingdomteams"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Heimdallr().V1alpha1().PingdomTeams().Informer()}, nil
 
 	}
 
@@ -30,6 +30,12 @@ import (
 type Interface interface {
 	// HTTPChecks returns a HTTPCheckInformer.
 	HTTPChecks() HTTPCheckInformer
+	// MaintenanceWindows returns a MaintenanceWindowInformer.
+	MaintenanceWindows() MaintenanceWindowInformer
+	// PingdomContacts returns a PingdomContactInformer.
+	PingdomContacts() PingdomContactInformer
+	// PingdomTeams returns a PingdomTeamInformer.
+	PingdomTeams() PingdomTeamInformer
 }
 
 type version struct {
@@ -47,3 +53,18 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (v *version) HTTPChecks() HTTPCheckInformer {
 	return &hTTPCheckInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// MaintenanceWindows returns a MaintenanceWindowInformer.
+func (v *version) MaintenanceWindows() MaintenanceWindowInformer {
+	return &maintenanceWindowInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// PingdomContacts returns a PingdomContactInformer.
+func (v *version) PingdomContacts() PingdomContactInformer {
+	return &pingdomContactInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// PingdomTeams returns a PingdomTeamInformer.
+func (v *version) PingdomTeams() PingdomTeamInformer {
+	return &pingdomTeamInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -29,3 +29,27 @@ type HTTPCheckListerExpansion interface{}
 // HTTPCheckNamespaceListerExpansion allows custom methods to be added to
 // HTTPCheckNamespaceLister.
 type HTTPCheckNamespaceListerExpansion interface{}
+
+// MaintenanceWindowListerExpansion allows custom methods to be added to
+// MaintenanceWindowLister.
+type MaintenanceWindowListerExpansion interface{}
+
+// MaintenanceWindowNamespaceListerExpansion allows custom methods to be added to
+// MaintenanceWindowNamespaceLister.
+type MaintenanceWindowNamespaceListerExpansion interface{}
+
+// PingdomContactListerExpansion allows custom methods to be added to
+// PingdomContactLister.
+type PingdomContactListerExpansion interface{}
+
+// PingdomContactNamespaceListerExpansion allows custom methods to be added to
+// PingdomContactNamespaceLister.
+type PingdomContactNamespaceListerExpansion interface{}
+
+// PingdomTeamListerExpansion allows custom methods to be added to
+// PingdomTeamLister.
+type PingdomTeamListerExpansion interface{}
+
+// PingdomTeamNamespaceListerExpansion allows custom methods to be added to
+// PingdomTeamNamespaceLister.
+type PingdomTeamNamespaceListerExpansion interface{}
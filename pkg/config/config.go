@@ -0,0 +1,238 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package config defines the configuration file of heimdallr.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/pingdom"
+
+	"github.com/ghodss/yaml"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Config is the configuration of the heimdallr controller.
+type Config struct {
+	Credentials       Credentials     `json:"credentials"`
+	Cluster           string          `json:"cluster,omitempty"`
+	Namespaces        []string        `json:"namespaces,omitempty"`
+	Selector          string          `json:"selector,omitempty"`
+	Workers           int             `json:"workers"`
+	ResyncPeriod      metav1.Duration `json:"resyncPeriod"`
+	ReconcileInterval metav1.Duration `json:"reconcileInterval"`
+	StatusInterval    metav1.Duration `json:"statusInterval"`
+	Uptime            Uptime          `json:"uptime"`
+	RateLimit         RateLimit       `json:"rateLimit"`
+	MetricsAddress    string          `json:"metricsAddress"`
+	NameTemplate      string          `json:"nameTemplate,omitempty"`
+	LabelTags         LabelTags       `json:"labelTags"`
+	Integrations      map[string]int  `json:"integrations,omitempty"`
+	Defaults          Defaults        `json:"defaults"`
+	Features          Features        `json:"features"`
+}
+
+// Credentials configures the Pingdom account checks are created in, either directly or by a
+// directory containing a file for each credential.
+type Credentials struct {
+	Username       string          `json:"username,omitempty"`
+	Password       string          `json:"password,omitempty"`
+	AppKey         string          `json:"appKey,omitempty"`
+	Token          string          `json:"token,omitempty"`
+	Dir            string          `json:"dir,omitempty"`
+	ReloadInterval metav1.Duration `json:"reloadInterval"`
+	ContactIDs     []int           `json:"contactIDs,omitempty"`
+}
+
+// LabelTags configures which labels of checks are added to them as Pingdom tags, those whose key
+// starts with the prefix and those whose key is listed.
+type LabelTags struct {
+	Prefix string   `json:"prefix,omitempty"`
+	Keys   []string `json:"keys,omitempty"`
+}
+
+// Uptime configures the windows over which the uptime of checks is recorded, and how long the
+// uptime read from Pingdom is cached for.
+type Uptime struct {
+	Windows         []metav1.Duration `json:"windows"`
+	RefreshInterval metav1.Duration   `json:"refreshInterval"`
+}
+
+// RateLimit configures the maximum rate of requests made to Pingdom. The rate is lowered further
+// when Pingdom reports that few requests remain within its own limits.
+type RateLimit struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	Burst             int     `json:"burst"`
+}
+
+// Defaults are the settings used for checks which don't specify them.
+type Defaults struct {
+	IntervalMinutes    int   `json:"intervalMinutes,omitempty"`
+	TriggerThreshold   int   `json:"triggerThreshold,omitempty"`
+	RetriggerThreshold int   `json:"retriggerThreshold,omitempty"`
+	IntegrationIDs     []int `json:"integrationIDs,omitempty"`
+}
+
+// Features toggles optional behaviour.
+type Features struct {
+	IngressDiscovery bool `json:"ingressDiscovery"`
+	ServiceDiscovery bool `json:"serviceDiscovery"`
+	DryRun           bool `json:"dryRun"`
+	Alerting         bool `json:"alerting"`
+	Maintenance      bool `json:"maintenance"`
+	RolloutPause     bool `json:"rolloutPause"`
+	DeleteOrphans    bool `json:"deleteOrphans"`
+}
+
+// validIntervals are the check intervals, in minutes, supported by Pingdom.
+var validIntervals = map[int]bool{1: true, 5: true, 15: true, 30: true, 60: true}
+
+// Default returns the default configuration.
+func Default() Config {
+	return Config{
+		Workers:           1,
+		MetricsAddress:    ":9090",
+		ReconcileInterval: metav1.Duration{Duration: 5 * time.Minute},
+		StatusInterval:    metav1.Duration{Duration: time.Minute},
+		Uptime: Uptime{
+			Windows: []metav1.Duration{
+				{Duration: 24 * time.Hour},
+				{Duration: 7 * 24 * time.Hour},
+				{Duration: 30 * 24 * time.Hour},
+			},
+			RefreshInterval: metav1.Duration{Duration: time.Hour},
+		},
+		RateLimit: RateLimit{
+			RequestsPerSecond: 5,
+			Burst:             10,
+		},
+		Credentials: Credentials{
+			ReloadInterval: metav1.Duration{Duration: 30 * time.Second},
+		},
+	}
+}
+
+// Load reads the configuration in the given file, using the default for any value it doesn't set.
+// Unknown fields are rejected so that a misspelled setting isn't silently ignored.
+func Load(path string) (Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read config: %v", err)
+	}
+
+	j, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to parse config: %v", err)
+	}
+
+	cfg := Default()
+	dec := json.NewDecoder(bytes.NewReader(j))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return Config{}, fmt.Errorf("failed to parse config: %v", err)
+	}
+
+	return cfg, nil
+}
+
+// Validate returns an error if the configuration is invalid.
+func (c Config) Validate() error {
+	creds := c.Credentials
+	if creds.Dir == "" && creds.Token == "" && (creds.Username == "" || creds.Password == "" || creds.AppKey == "") {
+		return fmt.Errorf("credentials must contain either a dir, a token or a username, password and app key")
+	}
+	if creds.ReloadInterval.Duration <= 0 {
+		return fmt.Errorf("credentials reload interval must be positive")
+	}
+
+	if c.Cluster != "" {
+		if errs := validation.IsDNS1123Label(c.Cluster); len(errs) > 0 {
+			return fmt.Errorf("invalid cluster %q: %v", c.Cluster, errs[0])
+		}
+	}
+
+	for _, ns := range c.Namespaces {
+		if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+			return fmt.Errorf("invalid namespace %q: %v", ns, errs[0])
+		}
+	}
+	if _, err := labels.Parse(c.Selector); err != nil {
+		return fmt.Errorf("invalid selector %q: %v", c.Selector, err)
+	}
+	if c.Features.DeleteOrphans && (len(c.Namespaces) > 0 || c.Selector != "") {
+		return fmt.Errorf("orphaned checks can only be deleted when every namespace is watched without a selector")
+	}
+
+	if c.Workers < 1 {
+		return fmt.Errorf("workers must be at least 1")
+	}
+	if c.ResyncPeriod.Duration < 0 {
+		return fmt.Errorf("resync period must not be negative")
+	}
+	if c.ReconcileInterval.Duration < 0 {
+		return fmt.Errorf("reconcile interval must not be negative")
+	}
+	if c.StatusInterval.Duration < 0 {
+		return fmt.Errorf("status interval must not be negative")
+	}
+	for _, window := range c.Uptime.Windows {
+		if window.Duration <= 0 {
+			return fmt.Errorf("uptime windows must be positive")
+		}
+	}
+	if c.Uptime.RefreshInterval.Duration <= 0 {
+		return fmt.Errorf("uptime refresh interval must be positive")
+	}
+	if c.RateLimit.RequestsPerSecond <= 0 {
+		return fmt.Errorf("rate limit must be positive")
+	}
+	if c.RateLimit.Burst < 1 {
+		return fmt.Errorf("rate limit burst must be at least 1")
+	}
+
+	if c.NameTemplate != "" {
+		if _, err := pingdom.ParseNameTemplate(c.NameTemplate); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range c.LabelTags.Keys {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid label tag key %q: %v", key, errs[0])
+		}
+	}
+
+	d := c.Defaults
+	if d.IntervalMinutes != 0 && !validIntervals[d.IntervalMinutes] {
+		return fmt.Errorf("default interval must be one of 1, 5, 15, 30 or 60 minutes")
+	}
+	if d.TriggerThreshold < 0 || d.RetriggerThreshold < 0 {
+		return fmt.Errorf("default thresholds must not be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// writeConfig writes the config to a temporary file, returning its path and a function which
+// removes it.
+func writeConfig(t *testing.T, contents string) (string, func()) {
+	dir, err := ioutil.TempDir("", "heimdallr")
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	return path, func() { _ = os.RemoveAll(dir) }
+}
+
+func TestLoad(t *testing.T) {
+	path, cleanup := writeConfig(t, `
+credentials:
+  dir: /etc/heimdallr/pingdom
+cluster: us-east-1
+namespaces: [web, api]
+selector: environment=production
+workers: 4
+nameTemplate: "{{.Namespace}}/{{.Name}}"
+integrations:
+  slack: 7
+labelTags:
+  prefix: pingdom.heimdallr.io/
+  keys: [app]
+resyncPeriod: 10m
+reconcileInterval: 1m
+statusInterval: 5m
+uptime:
+  windows: [1h, 24h]
+rateLimit:
+  requestsPerSecond: 0.5
+defaults:
+  intervalMinutes: 1
+  integrationIDs: [7]
+features:
+  ingressDiscovery: true
+`)
+	defer cleanup()
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate())
+
+	assert.Equal(t, "/etc/heimdallr/pingdom", cfg.Credentials.Dir)
+	assert.Equal(t, 30*time.Second, cfg.Credentials.ReloadInterval.Duration, "expected default to be kept")
+	assert.Equal(t, "us-east-1", cfg.Cluster)
+	assert.Equal(t, []string{"web", "api"}, cfg.Namespaces)
+	assert.Equal(t, "environment=production", cfg.Selector)
+	assert.Equal(t, 4, cfg.Workers)
+	assert.Equal(t, "{{.Namespace}}/{{.Name}}", cfg.NameTemplate)
+	assert.Equal(t, map[string]int{"slack": 7}, cfg.Integrations)
+	assert.Equal(t, LabelTags{Prefix: "pingdom.heimdallr.io/", Keys: []string{"app"}}, cfg.LabelTags)
+	assert.Equal(t, 10*time.Minute, cfg.ResyncPeriod.Duration)
+	assert.Equal(t, time.Minute, cfg.ReconcileInterval.Duration)
+	assert.Equal(t, 5*time.Minute, cfg.StatusInterval.Duration)
+	assert.Equal(t, Uptime{
+		Windows:         []metav1.Duration{{Duration: time.Hour}, {Duration: 24 * time.Hour}},
+		RefreshInterval: metav1.Duration{Duration: time.Hour},
+	}, cfg.Uptime, "expected default refresh interval to be kept")
+	assert.Equal(t, RateLimit{RequestsPerSecond: 0.5, Burst: 10}, cfg.RateLimit, "expected default burst to be kept")
+	assert.Equal(t, ":9090", cfg.MetricsAddress)
+	assert.Equal(t, Defaults{IntervalMinutes: 1, IntegrationIDs: []int{7}}, cfg.Defaults)
+	assert.Equal(t, Features{IngressDiscovery: true}, cfg.Features)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path, cleanup := writeConfig(t, "workers: [1]")
+	defer cleanup()
+
+	_, err := Load(path)
+	assert.Error(t, err)
+
+	path, cleanup = writeConfig(t, "credentials:\n  tokn: secret")
+	defer cleanup()
+
+	_, err = Load(path)
+	assert.Error(t, err, "expected unknown field to be rejected")
+
+	_, err = Load(filepath.Join(os.TempDir(), "does-not-exist.yaml"))
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() Config {
+		cfg := Default()
+		cfg.Credentials.Token = "token"
+		return cfg
+	}
+	require.NoError(t, valid().Validate())
+
+	tests := map[string]func(*Config){
+		"no credentials": func(c *Config) { c.Credentials = Credentials{ReloadInterval: c.Credentials.ReloadInterval} },
+		"partial credentials": func(c *Config) {
+			c.Credentials = Credentials{Username: "user", ReloadInterval: c.Credentials.ReloadInterval}
+		},
+		"reload interval": func(c *Config) { c.Credentials.ReloadInterval.Duration = 0 },
+		"cluster":         func(c *Config) { c.Cluster = "us_east" },
+		"namespace":       func(c *Config) { c.Namespaces = []string{"Web"} },
+		"selector":        func(c *Config) { c.Selector = "a=b=c" },
+		"orphans selector": func(c *Config) {
+			c.Selector = "team=web"
+			c.Features.DeleteOrphans = true
+		},
+		"orphans namespaces": func(c *Config) {
+			c.Namespaces = []string{"web"}
+			c.Features.DeleteOrphans = true
+		},
+		"workers":         func(c *Config) { c.Workers = 0 },
+		"resync period":   func(c *Config) { c.ResyncPeriod.Duration = -time.Second },
+		"reconcile":       func(c *Config) { c.ReconcileInterval.Duration = -time.Second },
+		"status interval": func(c *Config) { c.StatusInterval.Duration = -time.Second },
+		"uptime window":   func(c *Config) { c.Uptime.Windows = []metav1.Duration{{}} },
+		"uptime refresh":  func(c *Config) { c.Uptime.RefreshInterval.Duration = 0 },
+		"rate limit":      func(c *Config) { c.RateLimit.RequestsPerSecond = 0 },
+		"rate burst":      func(c *Config) { c.RateLimit.Burst = 0 },
+		"label tag key":   func(c *Config) { c.LabelTags.Keys = []string{"a b"} },
+		"interval":        func(c *Config) { c.Defaults.IntervalMinutes = 2 },
+		"threshold":       func(c *Config) { c.Defaults.TriggerThreshold = -1 },
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := valid()
+			mutate(&cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
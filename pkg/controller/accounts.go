@@ -0,0 +1,105 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package controller
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	"github.com/jeromefroe/heimdallr/pkg/pingdom"
+
+	"go.uber.org/zap"
+)
+
+// ClientFactory creates a Pingdom client for the account with the given credentials.
+type ClientFactory func(creds pingdom.Credentials) (*pingdom.Client, error)
+
+type clientFactory func(creds pingdom.Credentials) (PingdomClient, error)
+
+type account struct {
+	version string
+	client  PingdomClient
+}
+
+// accounts lazily creates and caches a Pingdom client for each Secret referenced by a check.
+type accounts struct {
+	creds   CredentialsGetter
+	factory clientFactory
+	logger  *zap.Logger
+
+	sync.Mutex
+	clients map[string]account
+}
+
+func newAccounts(creds CredentialsGetter, factory clientFactory, logger *zap.Logger) *accounts {
+	return &accounts{
+		creds:   creds,
+		factory: factory,
+		logger:  logger,
+		clients: make(map[string]account),
+	}
+}
+
+// client returns the client for the account referenced by the given Secret. The client is
+// recreated whenever the Secret changes.
+func (a *accounts) client(namespace string, ref v1alpha1.CredentialsReference) (PingdomClient, error) {
+	creds, version, err := a.creds.GetCredentials(namespace, ref.SecretName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials from secret %v/%v: %v", namespace, ref.SecretName, err)
+	}
+
+	key := namespace + "/" + ref.SecretName
+
+	a.Lock()
+	defer a.Unlock()
+
+	if acct, ok := a.clients[key]; ok && acct.version == version {
+		return acct.client, nil
+	}
+
+	a.logger.Info("creating client for pingdom account", zap.String("secret", key))
+	client, err := a.factory(creds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client from secret %v: %v", key, err)
+	}
+
+	a.clients[key] = account{version: version, client: client}
+	return client, nil
+}
+
+// cached returns the client last created for the account referenced by the given Secret, even if
+// the Secret no longer exists.
+func (a *accounts) cached(namespace string, ref v1alpha1.CredentialsReference) (PingdomClient, bool) {
+	a.Lock()
+	defer a.Unlock()
+	acct, ok := a.clients[namespace+"/"+ref.SecretName]
+	return acct.client, ok
+}
+
+// accountKey identifies the Pingdom account a check belongs to, the Secret it references or the
+// empty string for the controller's own account.
+func accountKey(chk *v1alpha1.HTTPCheck) string {
+	if ref := chk.Spec.CredentialsRef; ref != nil {
+		return chk.Namespace + "/" + ref.SecretName
+	}
+	return ""
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	"github.com/jeromefroe/heimdallr/pkg/pingdom"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAccountsClient(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	var (
+		ref   = v1alpha1.CredentialsReference{SecretName: "pingdom"}
+		creds = pingdom.Credentials{Username: "user", Password: "password", AppKey: "key"}
+	)
+
+	getter := NewMockCredentialsGetter(mCtrl)
+	gomock.InOrder(
+		getter.EXPECT().GetCredentials("team", "pingdom").Return(creds, "1", nil).Times(2),
+		getter.EXPECT().GetCredentials("team", "pingdom").Return(creds, "2", nil),
+	)
+
+	var created int
+	accts := newAccounts(getter, func(c pingdom.Credentials) (PingdomClient, error) {
+		assert.Equal(t, creds, c)
+		created++
+		return NewMockPingdomClient(mCtrl), nil
+	}, zap.NewNop())
+
+	first, err := accts.client("team", ref)
+	require.NoError(t, err)
+
+	second, err := accts.client("team", ref)
+	require.NoError(t, err)
+	assert.True(t, first == second, "expected cached client to be reused")
+	assert.Equal(t, 1, created)
+
+	third, err := accts.client("team", ref)
+	require.NoError(t, err)
+	assert.False(t, first == third, "expected client to be recreated after secret changed")
+	assert.Equal(t, 2, created)
+}
+
+func TestAccountsClientError(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	getter := NewMockCredentialsGetter(mCtrl)
+	getter.EXPECT().GetCredentials("team", "missing").Return(pingdom.Credentials{}, "", errors.New("not found"))
+
+	accts := newAccounts(getter, func(pingdom.Credentials) (PingdomClient, error) {
+		t.Fatal("unexpected call to client factory")
+		return nil, nil
+	}, zap.NewNop())
+
+	_, err := accts.client("team", v1alpha1.CredentialsReference{SecretName: "missing"})
+	assert.Error(t, err)
+}
+
+// newAccountsCheck returns a check referencing the credentials in the given Secret.
+func newAccountsCheck(secret string) v1alpha1.HTTPCheck {
+	return v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "check",
+			Namespace: "team",
+			UID:       "1",
+		},
+		Spec: v1alpha1.HTTPCheckSpec{
+			CredentialsRef: &v1alpha1.CredentialsReference{SecretName: secret},
+		},
+	}
+}
+
+func TestDeleteCheckSecretDeleted(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	check := newAccountsCheck("pingdom")
+
+	// The Secret is only read when the check is created, it's gone by the time it's deleted.
+	getter := NewMockCredentialsGetter(mCtrl)
+	getter.EXPECT().GetCredentials("team", "pingdom").Return(pingdom.Credentials{}, "1", nil)
+
+	acct := NewMockPingdomClient(mCtrl)
+	gomock.InOrder(
+		acct.EXPECT().UpdateHTTPCheck(check).Return(nil),
+		acct.EXPECT().DeleteHTTPCheck(gomock.Any()).Return(nil),
+	)
+
+	ctrl := new(NewMockPingdomClient(mCtrl), zap.NewNop())
+	ctrl.accounts = newAccounts(getter, func(pingdom.Credentials) (PingdomClient, error) {
+		return acct, nil
+	}, zap.NewNop())
+
+	ctrl.OnAdd(&check)
+	ctrl.processNextItem()
+	ctrl.OnDelete(&check)
+	ctrl.processNextItem()
+
+	_, ok := ctrl.syncedCheck("team/check")
+	assert.False(t, ok)
+}
+
+func TestUpdateCheckCredentialsRefChanged(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	var (
+		oldCheck = newAccountsCheck("old")
+		newCheck = newAccountsCheck("new")
+	)
+
+	getter := NewMockCredentialsGetter(mCtrl)
+	getter.EXPECT().GetCredentials("team", "old").Return(pingdom.Credentials{Token: "old"}, "1", nil)
+	getter.EXPECT().GetCredentials("team", "new").Return(pingdom.Credentials{Token: "new"}, "1", nil)
+
+	oldAcct := NewMockPingdomClient(mCtrl)
+	newAcct := NewMockPingdomClient(mCtrl)
+	gomock.InOrder(
+		oldAcct.EXPECT().UpdateHTTPCheck(oldCheck).Return(nil),
+		newAcct.EXPECT().UpdateHTTPCheck(newCheck).Return(nil),
+		oldAcct.EXPECT().DeleteHTTPCheck(gomock.Any()).Do(func(chk v1alpha1.HTTPCheck) {
+			assert.Equal(t, "old", chk.Spec.CredentialsRef.SecretName)
+		}).Return(nil),
+	)
+
+	ctrl := new(NewMockPingdomClient(mCtrl), zap.NewNop())
+	ctrl.accounts = newAccounts(getter, func(c pingdom.Credentials) (PingdomClient, error) {
+		if c.Token == "old" {
+			return oldAcct, nil
+		}
+		return newAcct, nil
+	}, zap.NewNop())
+
+	ctrl.OnAdd(&oldCheck)
+	ctrl.processNextItem()
+	ctrl.OnUpdate(&oldCheck, &newCheck)
+	ctrl.processNextItem()
+
+	synced, ok := ctrl.syncedCheck("team/check")
+	require.True(t, ok)
+	assert.Equal(t, "team/new", synced.account)
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package controller
+
+import (
+	"fmt"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ContactHandler watches for Pingdom contacts and translates them into calls to Pingdom. Teams are
+// updated again whenever a contact changes, so that teams created before their members are
+// completed once the members exist.
+type ContactHandler struct {
+	client AlertingClient
+	teams  *TeamHandler
+	queue  *objectQueue
+	logger *zap.Logger
+}
+
+// NewContactHandler creates a new contact handler. The teams of the given team handler, if any,
+// are updated again after a contact changes.
+func NewContactHandler(client AlertingClient, teams *TeamHandler, logger *zap.Logger) *ContactHandler {
+	h := &ContactHandler{
+		client: client,
+		teams:  teams,
+		logger: logger,
+	}
+	h.queue = newObjectQueue("contacts", h.update, h.delete, logger)
+	return h
+}
+
+// Run processes changes to contacts until stop is closed.
+func (h *ContactHandler) Run(stop <-chan struct{}) {
+	h.queue.run(stop)
+}
+
+// OnAdd handles new contacts.
+func (h *ContactHandler) OnAdd(obj interface{}) {
+	contact, ok := obj.(*v1alpha1.PingdomContact)
+	if !ok {
+		logUnexpected(h.logger, "OnAdd", obj)
+		return
+	}
+
+	h.queue.add(contact)
+}
+
+// OnUpdate handles updated contacts.
+func (h *ContactHandler) OnUpdate(oldObj, newObj interface{}) {
+	contact, ok := newObj.(*v1alpha1.PingdomContact)
+	if !ok {
+		logUnexpected(h.logger, "OnUpdate", newObj)
+		return
+	}
+
+	h.queue.add(contact)
+}
+
+// OnDelete handles deleted contacts.
+func (h *ContactHandler) OnDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	contact, ok := obj.(*v1alpha1.PingdomContact)
+	if !ok {
+		logUnexpected(h.logger, "OnDelete", obj)
+		return
+	}
+
+	h.queue.remove(contact)
+}
+
+func (h *ContactHandler) update(obj interface{}) error {
+	contact := obj.(*v1alpha1.PingdomContact)
+	if err := h.client.UpdateContact(*contact); err != nil {
+		h.logger.Error(
+			"unexpected error encountered updating contact",
+			zap.String("namespace", contact.Namespace),
+			zap.String("name", contact.Name),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	h.contactChanged()
+	return nil
+}
+
+func (h *ContactHandler) delete(obj interface{}) error {
+	contact := obj.(*v1alpha1.PingdomContact)
+	if err := h.client.DeleteContact(*contact); err != nil {
+		h.logger.Error(
+			"unexpected error encountered deleting contact",
+			zap.String("namespace", contact.Namespace),
+			zap.String("name", contact.Name),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	h.contactChanged()
+	return nil
+}
+
+func (h *ContactHandler) contactChanged() {
+	if h.teams != nil {
+		h.teams.queue.requeue(func(interface{}) bool { return true })
+	}
+}
+
+// TeamHandler watches for Pingdom teams and translates them into calls to Pingdom.
+type TeamHandler struct {
+	client AlertingClient
+	queue  *objectQueue
+	logger *zap.Logger
+}
+
+// NewTeamHandler creates a new team handler.
+func NewTeamHandler(client AlertingClient, logger *zap.Logger) *TeamHandler {
+	h := &TeamHandler{
+		client: client,
+		logger: logger,
+	}
+	h.queue = newObjectQueue("teams", h.update, h.delete, logger)
+	return h
+}
+
+// Run processes changes to teams until stop is closed.
+func (h *TeamHandler) Run(stop <-chan struct{}) {
+	h.queue.run(stop)
+}
+
+// OnAdd handles new teams.
+func (h *TeamHandler) OnAdd(obj interface{}) {
+	team, ok := obj.(*v1alpha1.PingdomTeam)
+	if !ok {
+		logUnexpected(h.logger, "OnAdd", obj)
+		return
+	}
+
+	h.queue.add(team)
+}
+
+// OnUpdate handles updated teams.
+func (h *TeamHandler) OnUpdate(oldObj, newObj interface{}) {
+	team, ok := newObj.(*v1alpha1.PingdomTeam)
+	if !ok {
+		logUnexpected(h.logger, "OnUpdate", newObj)
+		return
+	}
+
+	h.queue.add(team)
+}
+
+// OnDelete handles deleted teams.
+func (h *TeamHandler) OnDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	team, ok := obj.(*v1alpha1.PingdomTeam)
+	if !ok {
+		logUnexpected(h.logger, "OnDelete", obj)
+		return
+	}
+
+	h.queue.remove(team)
+}
+
+func (h *TeamHandler) update(obj interface{}) error {
+	team := obj.(*v1alpha1.PingdomTeam)
+	if err := h.client.UpdateTeam(*team); err != nil {
+		h.logger.Error(
+			"unexpected error encountered updating team",
+			zap.String("namespace", team.Namespace),
+			zap.String("name", team.Name),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (h *TeamHandler) delete(obj interface{}) error {
+	team := obj.(*v1alpha1.PingdomTeam)
+	if err := h.client.DeleteTeam(*team); err != nil {
+		h.logger.Error(
+			"unexpected error encountered deleting team",
+			zap.String("namespace", team.Namespace),
+			zap.String("name", team.Name),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func logUnexpected(logger *zap.Logger, fn string, obj interface{}) {
+	logger.Error(
+		"unexpected object received",
+		zap.String("function", fn),
+		zap.Any("object", obj),
+		zap.String("type", fmt.Sprintf("%T", obj)),
+	)
+}
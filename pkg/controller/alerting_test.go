@@ -0,0 +1,131 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	"github.com/jeromefroe/heimdallr/pkg/pingdom"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestContactHandler(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	contact := v1alpha1.PingdomContact{
+		ObjectMeta: metav1.ObjectMeta{Name: "alice"},
+		Spec: v1alpha1.PingdomContactSpec{
+			Email: []v1alpha1.EmailTarget{{Address: "alice@example.com"}},
+		},
+	}
+
+	cli := NewMockAlertingClient(mCtrl)
+	gomock.InOrder(
+		cli.EXPECT().UpdateContact(contact).Return(nil),
+		cli.EXPECT().UpdateContact(contact).Return(errors.New("bad request")),
+		cli.EXPECT().DeleteContact(contact).Return(nil),
+	)
+
+	h := NewContactHandler(cli, nil, zap.NewNop())
+	h.OnAdd(&contact)
+	h.queue.processNextItem()
+
+	// Failures are retried.
+	h.OnUpdate(&contact, &contact)
+	h.queue.processNextItem()
+	assert.Equal(t, 1, h.queue.NumRequeues("alice"))
+
+	h.OnDelete(&contact)
+	h.queue.processNextItem()
+
+	// Unexpected objects are ignored.
+	h.OnAdd(&v1alpha1.PingdomTeam{})
+}
+
+func TestTeamHandler(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	team := v1alpha1.PingdomTeam{
+		ObjectMeta: metav1.ObjectMeta{Name: "payments"},
+		Spec:       v1alpha1.PingdomTeamSpec{Members: []string{"alice"}},
+	}
+
+	cli := NewMockAlertingClient(mCtrl)
+	gomock.InOrder(
+		cli.EXPECT().UpdateTeam(team).Return(nil),
+		cli.EXPECT().DeleteTeam(team).Return(nil),
+		cli.EXPECT().DeleteTeam(team).Return(nil),
+	)
+
+	h := NewTeamHandler(cli, zap.NewNop())
+	h.OnAdd(&team)
+	h.queue.processNextItem()
+	h.OnDelete(&team)
+	h.queue.processNextItem()
+	h.OnDelete(cache.DeletedFinalStateUnknown{Key: "payments", Obj: &team})
+	h.queue.processNextItem()
+	h.OnDelete(&v1alpha1.PingdomContact{})
+	assert.Equal(t, 0, h.queue.Len())
+}
+
+func TestContactHandlerUpdatesTeams(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	var (
+		contact = v1alpha1.PingdomContact{ObjectMeta: metav1.ObjectMeta{Name: "alice"}}
+		team    = v1alpha1.PingdomTeam{
+			ObjectMeta: metav1.ObjectMeta{Name: "payments"},
+			Spec:       v1alpha1.PingdomTeamSpec{Members: []string{"alice"}},
+		}
+	)
+
+	cli := NewMockAlertingClient(mCtrl)
+	gomock.InOrder(
+		cli.EXPECT().UpdateTeam(team).Return(&pingdom.UnresolvedReferenceError{Kind: "contact", Name: "alice"}),
+		cli.EXPECT().UpdateContact(contact).Return(nil),
+		cli.EXPECT().UpdateTeam(team).Return(nil),
+	)
+
+	teams := NewTeamHandler(cli, zap.NewNop())
+	contacts := NewContactHandler(cli, teams, zap.NewNop())
+
+	// A team created before its members waits for them rather than being retried.
+	teams.OnAdd(&team)
+	teams.queue.processNextItem()
+	assert.Equal(t, 0, teams.queue.NumRequeues("payments"))
+	assert.Equal(t, 0, teams.queue.Len())
+
+	// The team is updated again once a contact is created.
+	contacts.OnAdd(&contact)
+	contacts.queue.processNextItem()
+	assert.Equal(t, 1, teams.queue.Len())
+	teams.queue.processNextItem()
+}
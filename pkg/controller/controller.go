@@ -23,29 +23,148 @@ package controller
 
 import (
 	"fmt"
+	"reflect"
+	"sync"
+	"time"
 
 	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
 	"github.com/jeromefroe/heimdallr/pkg/pingdom"
 
 	"go.uber.org/zap"
+	"k8s.io/client-go/tools/cache"
 )
 
+type eventType int
+
+const (
+	eventAdd eventType = iota
+	eventUpdate
+	eventDelete
+)
+
+// event is the latest change to a check which hasn't been processed yet.
+type event struct {
+	typ   eventType
+	check *v1alpha1.HTTPCheck
+}
+
 // Controller watches for heimdallr checks and translates them into calls to Pingdom.
 type Controller struct {
-	client pingdomClient
-	logger *zap.Logger
+	client   PingdomClient
+	accounts *accounts
+	status   StatusUpdater
+	rollouts RolloutGetter
+	checks   CheckLister
+	windows  *MaintenanceHandler
+	queue    *changeQueue
+	logger   *zap.Logger
+	dryRun   bool
+
+	// reconcile configures how often the checks in namespaces are listed to find the checks
+	// which were deleted without the controller being notified.
+	reconcile  time.Duration
+	namespaces []string
+	orphans    OrphanClient
+	selector   string
+
+	mu     sync.Mutex
+	synced map[string]syncedCheck
+}
+
+// syncedCheck is a check as it was last synced with Pingdom, along with the client of the account
+// it was synced with, so that it can be deleted from that account even once its credentials are
+// gone or it references another account.
+type syncedCheck struct {
+	check   *v1alpha1.HTTPCheck
+	account string
+	client  PingdomClient
+}
+
+// Option configures a Controller.
+type Option func(*Controller)
+
+// WithAccounts configures the controller to manage checks which reference the credentials of
+// another Pingdom account with clients created by the given factory.
+func WithAccounts(creds CredentialsGetter, factory ClientFactory) Option {
+	return func(c *Controller) {
+		c.accounts = newAccounts(creds, func(creds pingdom.Credentials) (PingdomClient, error) {
+			client, err := factory(creds)
+			if err != nil {
+				return nil, err
+			}
+			return client, nil
+		}, c.logger)
+	}
+}
+
+// WithStatusUpdater configures the controller to persist the status of checks after creating or
+// updating them.
+func WithStatusUpdater(status StatusUpdater) Option {
+	return func(c *Controller) {
+		c.status = status
+	}
+}
+
+// WithRollouts configures the controller to pause checks while the Deployment named by their
+// PauseDuringRolloutAnnotation is rolling out. The checks listed by the given lister are synced
+// again when RolloutChanged is called for a Deployment.
+func WithRollouts(rollouts RolloutGetter, checks CheckLister) Option {
+	return func(c *Controller) {
+		c.rollouts = rollouts
+		c.checks = checks
+	}
+}
+
+// WithMaintenance configures the controller to update the maintenance windows of the given
+// handler after a check in their namespace is created, updated or deleted in Pingdom, so that
+// windows include the checks they select regardless of the order they're created in.
+func WithMaintenance(windows *MaintenanceHandler) Option {
+	return func(c *Controller) {
+		c.windows = windows
+	}
+}
+
+// WithDryRun configures whether the client of the controller only logs the changes it would make
+// to checks, so that the status of checks reports that they weren't synced.
+func WithDryRun(dryRun bool) Option {
+	return func(c *Controller) {
+		c.dryRun = dryRun
+	}
 }
 
 // New creates a new controller.
-func New(client *pingdom.Client, logger *zap.Logger) *Controller {
-	return new(client, logger)
+func New(client PingdomClient, logger *zap.Logger, opts ...Option) *Controller {
+	return new(client, logger, opts...)
 }
 
-func new(client pingdomClient, logger *zap.Logger) *Controller {
-	return &Controller{
+func new(client PingdomClient, logger *zap.Logger, opts ...Option) *Controller {
+	c := &Controller{
 		client: client,
+		queue:  newChangeQueue("httpchecks", logger),
 		logger: logger,
+		synced: make(map[string]syncedCheck),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Run processes changes to checks with the given number of workers until stop is closed.
+func (c *Controller) Run(workers int, stop <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for c.processNextItem() {
+			}
+		}()
+	}
+	if c.reconcile > 0 {
+		go c.runReconciler(stop)
+	}
+
+	<-stop
 }
 
 // OnAdd handles new HTTP checks.
@@ -56,15 +175,7 @@ func (c *Controller) OnAdd(obj interface{}) {
 		return
 	}
 
-	err := c.client.UpdateHTTPCheck(*chk)
-	if err != nil {
-		chk.Status.State = fmt.Sprintf("failed to create check: %v", err)
-		c.logger.Error("unexpected error encountered adding check", zap.Error(err))
-		return
-	}
-
-	chk.Status.State = "successfully created check"
-	c.logger.Info("OnAdd successful", zap.String("name", chk.Name))
+	c.enqueue(eventAdd, chk)
 }
 
 // OnUpdate handles updates HTTP checks.
@@ -77,51 +188,247 @@ func (c *Controller) OnUpdate(oldObj, newObj interface{}) {
 	newChk, ok := newObj.(*v1alpha1.HTTPCheck)
 	if !ok {
 		c.logUnexpected("OnUpdate", newObj)
-	}
-
-	if oldChk == newChk {
-		c.logger.Info(
-			"new and old checks are identical so no further work is required",
-			zap.String("name", newChk.Name),
-		)
 		return
 	}
 
-	err := c.client.UpdateHTTPCheck(*newChk)
-	if err != nil {
-		newChk.Status.State = fmt.Sprintf("failed to update check: %v", err)
-		c.logger.Error("unexpected error encountered updating check", zap.Error(err))
+	// Resyncs deliver the same check as both the old and new one. They're still synced, since
+	// the Pingdom client only updates checks which differ from their last known state.
+	if oldChk != nil && oldChk != newChk && onlyStatusChanged(oldChk, newChk) {
+		// The controller updated the status of the check, which doesn't need to be synced again.
 		return
 	}
 
-	newChk.Status.State = "successfully updated check"
-	c.logger.Info("OnUpdate successful", zap.String("name", newChk.Name))
+	c.enqueue(eventUpdate, newChk)
 }
 
-// OnDelete handles deleted HTTP checks.
+// OnDelete handles deleted HTTP checks, including those whose deletion was only noticed when the
+// informer relisted checks, such as after its watch was disconnected.
 func (c *Controller) OnDelete(obj interface{}) {
+	tombstone, isTombstone := obj.(cache.DeletedFinalStateUnknown)
+	if isTombstone {
+		obj = tombstone.Obj
+	}
+
 	chk, ok := obj.(*v1alpha1.HTTPCheck)
+	if !ok && isTombstone {
+		// The final state of the check is unknown, but the controller knows the check it synced.
+		var synced syncedCheck
+		if synced, ok = c.syncedCheck(tombstone.Key); ok {
+			chk = synced.check
+		}
+	}
 	if !ok {
 		c.logUnexpected("OnDelete", obj)
 		return
 	}
 
-	err := c.client.DeleteHTTPCheck(*chk)
+	c.enqueue(eventDelete, chk)
+}
+
+// enqueue records the latest change to a check and queues it to be processed. Only the latest
+// change is processed if a check changes multiple times before a worker gets to it.
+func (c *Controller) enqueue(typ eventType, chk *v1alpha1.HTTPCheck) {
+	c.queue.enqueue(checkKey(chk), event{typ: typ, check: chk})
+}
+
+// processNextItem processes the next check in the queue, returning false once the queue has
+// been shut down.
+func (c *Controller) processNextItem() bool {
+	return c.queue.process(func(change interface{}) error {
+		return c.handle(change.(event))
+	})
+}
+
+func (c *Controller) handle(ev event) error {
+	switch ev.typ {
+	case eventAdd:
+		return c.addCheck(ev.check)
+	case eventUpdate:
+		return c.updateCheck(ev.check)
+	default:
+		return c.deleteCheck(ev.check)
+	}
+}
+
+func (c *Controller) addCheck(chk *v1alpha1.HTTPCheck) error {
+	// The check is shared with the informer's cache, so its status is changed on a copy.
+	updated := chk.DeepCopy()
+
+	client, err := c.clientFor(chk)
+	if err != nil {
+		c.setStatus(chk, updated, fmt.Sprintf("failed to create check: %v", err), err)
+		c.logger.Error("unexpected error encountered adding check", zap.Error(err))
+		return err
+	}
+
+	err = client.UpdateHTTPCheck(c.withPause(updated))
+	if err == nil {
+		err = c.deleteFromPreviousAccount(chk)
+	}
+	if err != nil {
+		c.setStatus(chk, updated, fmt.Sprintf("failed to create check: %v", err), err)
+		c.logger.Error("unexpected error encountered adding check", zap.Error(err))
+		return err
+	}
+
+	selectionChanged := c.selectionChanged(chk)
+	c.setSynced(chk, client)
+	c.setStatus(chk, updated, c.successState("created"), nil)
+	if selectionChanged {
+		c.checkChanged(chk)
+	}
+	c.logger.Info("OnAdd successful", zap.String("name", chk.Name))
+	return nil
+}
+
+func (c *Controller) updateCheck(chk *v1alpha1.HTTPCheck) error {
+	// The check is shared with the informer's cache, so its status is changed on a copy.
+	updated := chk.DeepCopy()
+
+	client, err := c.clientFor(chk)
+	if err != nil {
+		c.setStatus(chk, updated, fmt.Sprintf("failed to update check: %v", err), err)
+		c.logger.Error("unexpected error encountered updating check", zap.Error(err))
+		return err
+	}
+
+	err = client.UpdateHTTPCheck(c.withPause(updated))
+	if err == nil {
+		err = c.deleteFromPreviousAccount(chk)
+	}
+	if err != nil {
+		c.setStatus(chk, updated, fmt.Sprintf("failed to update check: %v", err), err)
+		c.logger.Error("unexpected error encountered updating check", zap.Error(err))
+		return err
+	}
+
+	selectionChanged := c.selectionChanged(chk)
+	c.setSynced(chk, client)
+	c.setStatus(chk, updated, c.successState("updated"), nil)
+	if selectionChanged {
+		c.checkChanged(chk)
+	}
+	c.logger.Info("OnUpdate successful", zap.String("name", chk.Name))
+	return nil
+}
+
+func (c *Controller) deleteCheck(chk *v1alpha1.HTTPCheck) error {
+	// The check no longer exists, so the outcome is only logged rather than recorded in its
+	// status.
+	client, err := c.deleteClientFor(chk)
 	if err != nil {
-		chk.Status.State = fmt.Sprintf("failed to delete check: %v", err)
 		c.logger.Error("unexpected error encountered deleting check", zap.Error(err))
-		return
+		return err
 	}
 
-	chk.Status.State = "successfully deleted check"
+	err = client.DeleteHTTPCheck(*chk)
+	if err != nil {
+		c.logger.Error("unexpected error encountered deleting check", zap.Error(err))
+		return err
+	}
+
+	c.forgetSynced(chk)
+	c.checkChanged(chk)
 	c.logger.Info("OnDelete successful", zap.String("name", chk.Name))
+	return nil
+}
+
+// checkChanged updates the maintenance windows which may select the check.
+func (c *Controller) checkChanged(chk *v1alpha1.HTTPCheck) {
+	if c.windows != nil {
+		c.windows.CheckChanged(chk)
+	}
+}
+
+// selectionChanged returns whether the maintenance windows which select the check may have
+// changed since it was last synced, because it wasn't synced yet or its labels changed. Resyncs
+// of unchanged checks don't update every window in their namespace.
+func (c *Controller) selectionChanged(chk *v1alpha1.HTTPCheck) bool {
+	synced, ok := c.syncedCheck(checkKey(chk))
+	return !ok || synced.check.UID != chk.UID || !reflect.DeepEqual(synced.check.Labels, chk.Labels)
+}
+
+// successState returns the state of a check which was successfully created or updated.
+func (c *Controller) successState(action string) string {
+	if c.dryRun {
+		return "would have " + action + " check in dry run"
+	}
+	return "successfully " + action + " check"
 }
 
-func (c Controller) logUnexpected(fn string, obj interface{}) {
-	c.logger.Error(
-		"unexpected object received",
-		zap.String("function", fn),
-		zap.Any("object", obj),
-		zap.String("type", fmt.Sprintf("%T", obj)),
+// setStatus records the outcome of creating or updating a check in the status of the updated copy
+// of the check and persists it, unless the status of the check already matches.
+func (c *Controller) setStatus(chk, updated *v1alpha1.HTTPCheck, state string, err error) {
+	cond := readyCondition(err)
+	if err == nil && c.dryRun {
+		cond = dryRunCondition()
+	}
+
+	updated.Status.State = state
+	setCondition(&updated.Status, cond)
+
+	if c.status == nil || reflect.DeepEqual(chk.Status, updated.Status) {
+		return
+	}
+	if err := c.status.UpdateStatus(updated); err != nil {
+		c.logger.Warn("unable to update status of check", zap.String("name", chk.Name), zap.Error(err))
+	}
+}
+
+// onlyStatusChanged returns whether the checks only differ in their status, as they do after the
+// controller updates the status of a check.
+func onlyStatusChanged(oldChk, newChk *v1alpha1.HTTPCheck) bool {
+	return reflect.DeepEqual(oldChk.Spec, newChk.Spec) &&
+		reflect.DeepEqual(oldChk.Labels, newChk.Labels) &&
+		reflect.DeepEqual(oldChk.Annotations, newChk.Annotations)
+}
+
+// deleteClientFor returns the client for the Pingdom account the check was synced with, which
+// doesn't need its credentials to still exist.
+func (c *Controller) deleteClientFor(chk *v1alpha1.HTTPCheck) (PingdomClient, error) {
+	if synced, ok := c.syncedCheck(checkKey(chk)); ok && synced.check.UID == chk.UID {
+		return synced.client, nil
+	}
+
+	client, err := c.clientFor(chk)
+	if err != nil && chk.Spec.CredentialsRef != nil && c.accounts != nil {
+		// The check wasn't synced since the controller started, but its account may still be
+		// known from other checks referencing the same credentials.
+		if cached, ok := c.accounts.cached(chk.Namespace, *chk.Spec.CredentialsRef); ok {
+			return cached, nil
+		}
+	}
+	return client, err
+}
+
+// deleteFromPreviousAccount deletes the check from the Pingdom account it was last synced with,
+// if its credentials reference changed since.
+func (c *Controller) deleteFromPreviousAccount(chk *v1alpha1.HTTPCheck) error {
+	synced, ok := c.syncedCheck(checkKey(chk))
+	if !ok || synced.check.UID != chk.UID || synced.account == accountKey(chk) {
+		return nil
+	}
+
+	c.logger.Info(
+		"deleting check from the pingdom account it no longer references",
+		zap.String("name", chk.Name),
+		zap.String("account", synced.account),
 	)
+	return synced.client.DeleteHTTPCheck(*synced.check)
+}
+
+// clientFor returns the client for the Pingdom account the check belongs to.
+func (c *Controller) clientFor(chk *v1alpha1.HTTPCheck) (PingdomClient, error) {
+	ref := chk.Spec.CredentialsRef
+	if ref == nil {
+		return c.client, nil
+	}
+	if c.accounts == nil {
+		return nil, fmt.Errorf("check references secret %v but credential references are not enabled", ref.SecretName)
+	}
+	return c.accounts.client(chk.Namespace, *ref)
+}
+
+func (c *Controller) logUnexpected(fn string, obj interface{}) {
+	logUnexpected(c.logger, fn, obj)
 }
@@ -25,13 +25,24 @@ import (
 	"testing"
 
 	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	"github.com/jeromefroe/heimdallr/pkg/pingdom"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// statusRecorder is a StatusUpdater which records the last status persisted for each check, by
+// name.
+type statusRecorder map[string]v1alpha1.HTTPCheckStatus
+
+func (r statusRecorder) UpdateStatus(chk *v1alpha1.HTTPCheck) error {
+	r[chk.Name] = chk.Status
+	return nil
+}
+
 func TestOnAdd(t *testing.T) {
 	mCtrl := gomock.NewController(t)
 	defer mCtrl.Finish()
@@ -42,13 +53,16 @@ func TestOnAdd(t *testing.T) {
 		},
 	}
 
-	cli := NewMockpingdomClient(mCtrl)
+	cli := NewMockPingdomClient(mCtrl)
 	cli.EXPECT().UpdateHTTPCheck(check).Return(nil)
 
-	ctrl := new(cli, zap.NewNop())
+	status := statusRecorder{}
+	ctrl := new(cli, zap.NewNop(), WithStatusUpdater(status))
 	ctrl.OnAdd(&check)
+	ctrl.processNextItem()
 
-	assert.Contains(t, check.Status.State, "success")
+	assert.Contains(t, status["check"].State, "success")
+	assert.Empty(t, check.Status.State, "expected the check in the cache not to be modified")
 }
 
 func TestOnAddError(t *testing.T) {
@@ -61,13 +75,15 @@ func TestOnAddError(t *testing.T) {
 		},
 	}
 
-	cli := NewMockpingdomClient(mCtrl)
+	cli := NewMockPingdomClient(mCtrl)
 	cli.EXPECT().UpdateHTTPCheck(check).Return(errors.New("bad requests"))
 
-	ctrl := new(cli, zap.NewNop())
+	status := statusRecorder{}
+	ctrl := new(cli, zap.NewNop(), WithStatusUpdater(status))
 	ctrl.OnAdd(&check)
+	ctrl.processNextItem()
 
-	assert.Contains(t, check.Status.State, "fail")
+	assert.Contains(t, status["check"].State, "fail")
 }
 
 func TestOnUpdate(t *testing.T) {
@@ -93,13 +109,37 @@ func TestOnUpdate(t *testing.T) {
 		}
 	)
 
-	cli := NewMockpingdomClient(mCtrl)
+	cli := NewMockPingdomClient(mCtrl)
 	cli.EXPECT().UpdateHTTPCheck(newCheck).Return(nil)
 
-	ctrl := new(cli, zap.NewNop())
+	status := statusRecorder{}
+	ctrl := new(cli, zap.NewNop(), WithStatusUpdater(status))
 	ctrl.OnUpdate(&oldCheck, &newCheck)
+	ctrl.processNextItem()
+
+	assert.Contains(t, status["check"].State, "success")
+}
+
+func TestOnUpdateResync(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "check",
+		},
+	}
 
-	assert.Contains(t, newCheck.Status.State, "success")
+	// A resync delivers the same check as both the old and new one, and is still synced.
+	cli := NewMockPingdomClient(mCtrl)
+	cli.EXPECT().UpdateHTTPCheck(check).Return(nil)
+
+	status := statusRecorder{}
+	ctrl := new(cli, zap.NewNop(), WithStatusUpdater(status))
+	ctrl.OnUpdate(&check, &check)
+	ctrl.processNextItem()
+
+	assert.Contains(t, status["check"].State, "success")
 }
 
 func TestOnUpdateError(t *testing.T) {
@@ -125,13 +165,15 @@ func TestOnUpdateError(t *testing.T) {
 		}
 	)
 
-	cli := NewMockpingdomClient(mCtrl)
+	cli := NewMockPingdomClient(mCtrl)
 	cli.EXPECT().UpdateHTTPCheck(newCheck).Return(errors.New("bad request"))
 
-	ctrl := new(cli, zap.NewNop())
+	status := statusRecorder{}
+	ctrl := new(cli, zap.NewNop(), WithStatusUpdater(status))
 	ctrl.OnUpdate(&oldCheck, &newCheck)
+	ctrl.processNextItem()
 
-	assert.Contains(t, newCheck.Status.State, "fail")
+	assert.Contains(t, status["check"].State, "fail")
 }
 
 func TestOnDelete(t *testing.T) {
@@ -144,13 +186,14 @@ func TestOnDelete(t *testing.T) {
 		},
 	}
 
-	cli := NewMockpingdomClient(mCtrl)
+	cli := NewMockPingdomClient(mCtrl)
 	cli.EXPECT().DeleteHTTPCheck(check).Return(nil)
 
 	ctrl := new(cli, zap.NewNop())
 	ctrl.OnDelete(&check)
+	ctrl.processNextItem()
 
-	assert.Contains(t, check.Status.State, "success")
+	assert.Equal(t, 0, ctrl.queue.NumRequeues("/check"))
 }
 
 func TestOnDeleteError(t *testing.T) {
@@ -163,11 +206,216 @@ func TestOnDeleteError(t *testing.T) {
 		},
 	}
 
-	cli := NewMockpingdomClient(mCtrl)
+	cli := NewMockPingdomClient(mCtrl)
 	cli.EXPECT().DeleteHTTPCheck(check).Return(errors.New("bad requests"))
 
 	ctrl := new(cli, zap.NewNop())
 	ctrl.OnDelete(&check)
+	ctrl.processNextItem()
+
+	assert.Equal(t, 1, ctrl.queue.NumRequeues("/check"))
+}
+
+func TestOnAddWithCredentialsRef(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "check",
+			Namespace: "team",
+		},
+		Spec: v1alpha1.HTTPCheckSpec{
+			CredentialsRef: &v1alpha1.CredentialsReference{SecretName: "pingdom"},
+		},
+	}
+
+	getter := NewMockCredentialsGetter(mCtrl)
+	getter.EXPECT().GetCredentials("team", "pingdom").Return(pingdom.Credentials{}, "1", nil)
+
+	// The default client must not be used for checks referencing another account.
+	cli := NewMockPingdomClient(mCtrl)
+	acct := NewMockPingdomClient(mCtrl)
+	acct.EXPECT().UpdateHTTPCheck(check).Return(nil)
+
+	status := statusRecorder{}
+	ctrl := new(cli, zap.NewNop(), WithStatusUpdater(status))
+	ctrl.accounts = newAccounts(getter, func(pingdom.Credentials) (PingdomClient, error) {
+		return acct, nil
+	}, zap.NewNop())
+	ctrl.OnAdd(&check)
+	ctrl.processNextItem()
+
+	assert.Contains(t, status["check"].State, "success")
+}
+
+func TestOnAddWithCredentialsRefDisabled(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "check",
+		},
+		Spec: v1alpha1.HTTPCheckSpec{
+			CredentialsRef: &v1alpha1.CredentialsReference{SecretName: "pingdom"},
+		},
+	}
+
+	status := statusRecorder{}
+	ctrl := new(NewMockPingdomClient(mCtrl), zap.NewNop(), WithStatusUpdater(status))
+	ctrl.OnAdd(&check)
+	ctrl.processNextItem()
+
+	assert.Contains(t, status["check"].State, "fail")
+}
+
+func TestProcessNextItemRetries(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "check",
+		},
+	}
+
+	cli := NewMockPingdomClient(mCtrl)
+	gomock.InOrder(
+		cli.EXPECT().UpdateHTTPCheck(check).Return(errors.New("bad request")),
+		cli.EXPECT().UpdateHTTPCheck(gomock.Any()).Return(nil),
+	)
+
+	status := statusRecorder{}
+	ctrl := new(cli, zap.NewNop(), WithStatusUpdater(status))
+	ctrl.OnAdd(&check)
+
+	ctrl.processNextItem()
+	assert.Contains(t, status["check"].State, "fail")
+	assert.Equal(t, 1, ctrl.queue.NumRequeues("/check"))
+
+	ctrl.processNextItem()
+	assert.Contains(t, status["check"].State, "success")
+	assert.Equal(t, 0, ctrl.queue.NumRequeues("/check"))
+}
+
+func TestProcessNextItemPermanentFailure(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "check",
+		},
+	}
+
+	// An invalid check fails the same way every time, so it isn't retried.
+	cli := NewMockPingdomClient(mCtrl)
+	cli.EXPECT().
+		UpdateHTTPCheck(check).
+		Return(&pingdom.ValidationError{Op: "create check", Field: "hostname", Message: "Invalid hostname"})
+
+	status := statusRecorder{}
+	ctrl := new(cli, zap.NewNop(), WithStatusUpdater(status))
+	ctrl.OnAdd(&check)
+
+	ctrl.processNextItem()
+	assert.Contains(t, status["check"].State, "invalid hostname")
+	assert.Equal(t, 0, ctrl.queue.NumRequeues("/check"))
+	assert.Equal(t, 0, ctrl.queue.Len())
+}
+
+func TestProcessNextItemUnauthorized(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "check",
+		},
+	}
+
+	// A check whose credentials are rejected is retried until they're fixed, even after
+	// maxRetries failures.
+	cli := NewMockPingdomClient(mCtrl)
+	gomock.InOrder(
+		cli.EXPECT().
+			UpdateHTTPCheck(gomock.Any()).
+			Times(maxRetries+1).
+			Return(&pingdom.AuthError{Op: "create check", Message: "Invalid token"}),
+		cli.EXPECT().UpdateHTTPCheck(gomock.Any()).Return(nil),
+	)
+
+	status := statusRecorder{}
+	ctrl := new(cli, zap.NewNop(), WithStatusUpdater(status))
+	ctrl.OnAdd(&check)
+
+	for i := 0; i <= maxRetries; i++ {
+		ctrl.processNextItem()
+		require.Len(t, status["check"].Conditions, 1)
+		assert.Equal(t, reasonUnauthorized, status["check"].Conditions[0].Reason)
+		assert.Equal(t, i+1, ctrl.queue.NumRequeues("/check"))
+	}
+
+	ctrl.processNextItem()
+	assert.Contains(t, status["check"].State, "success")
+	assert.Equal(t, 0, ctrl.queue.NumRequeues("/check"))
+}
+
+func TestProcessNextItemLatestChange(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	var (
+		check = v1alpha1.HTTPCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "check",
+			},
+		}
+		updated = v1alpha1.HTTPCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "check",
+			},
+			Spec: v1alpha1.HTTPCheckSpec{
+				EnableTLS: true,
+			},
+		}
+	)
+
+	// Only the latest change is processed when a check changes before it's processed.
+	cli := NewMockPingdomClient(mCtrl)
+	cli.EXPECT().UpdateHTTPCheck(updated).Return(nil)
+
+	status := statusRecorder{}
+	ctrl := new(cli, zap.NewNop(), WithStatusUpdater(status))
+	ctrl.OnAdd(&check)
+	ctrl.OnUpdate(&check, &updated)
+	ctrl.processNextItem()
+
+	assert.Contains(t, status["check"].State, "success")
+	assert.Equal(t, 0, ctrl.queue.Len())
+}
+
+func TestOnAddDryRun(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "check",
+		},
+	}
+
+	cli := NewMockPingdomClient(mCtrl)
+	cli.EXPECT().UpdateHTTPCheck(check).Return(nil)
+
+	status := statusRecorder{}
+	ctrl := new(cli, zap.NewNop(), WithDryRun(true), WithStatusUpdater(status))
+	ctrl.OnAdd(&check)
+	ctrl.processNextItem()
 
-	assert.Contains(t, check.Status.State, "fail")
+	assert.Contains(t, status["check"].State, "dry run")
+	require.Len(t, status["check"].Conditions, 1)
+	assert.Equal(t, v1alpha1.ConditionUnknown, status["check"].Conditions[0].Status)
+	assert.Equal(t, reasonDryRun, status["check"].Conditions[0].Reason)
 }
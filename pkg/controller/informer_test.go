@@ -0,0 +1,194 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	"github.com/jeromefroe/heimdallr/pkg/client/clientset/versioned/fake"
+	"github.com/jeromefroe/heimdallr/pkg/client/informers/externalversions"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// watchDropper hands out watches on checks which report no events, so that changes to checks
+// are only noticed when the informer relists checks after a watch is dropped.
+type watchDropper struct {
+	watches chan *watch.FakeWatcher
+}
+
+func newWatchDropper(cs *fake.Clientset) *watchDropper {
+	d := &watchDropper{watches: make(chan *watch.FakeWatcher, 10)}
+	cs.PrependWatchReactor("httpchecks", func(clienttesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFake()
+		d.watches <- w
+		return true, w, nil
+	})
+	return d
+}
+
+// drop disconnects the informer's current watch.
+func (d *watchDropper) drop(t *testing.T) {
+	select {
+	case w := <-d.watches:
+		w.Stop()
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the informer to watch checks")
+	}
+}
+
+// clientsetLister lists checks directly from a clientset.
+type clientsetLister struct {
+	cs *fake.Clientset
+}
+
+func (l clientsetLister) ListChecks(namespace, selector string) ([]v1alpha1.HTTPCheck, error) {
+	list, err := l.cs.HeimdallrV1alpha1().HTTPChecks(namespace).List(metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func newInformerCheck(name, uid string) *v1alpha1.HTTPCheck {
+	return &v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			UID:       types.UID(uid),
+		},
+	}
+}
+
+// runInformer runs the controller with a shared informer for the checks in the clientset, and
+// returns a channel on which the names of the checks updated and deleted in Pingdom are sent.
+func runInformer(
+	mCtrl *gomock.Controller,
+	cs *fake.Clientset,
+	stop <-chan struct{},
+	opts ...Option,
+) (updated, deleted <-chan string) {
+	up := make(chan string, 10)
+	del := make(chan string, 10)
+
+	cli := NewMockPingdomClient(mCtrl)
+	cli.EXPECT().UpdateHTTPCheck(gomock.Any()).Do(func(chk v1alpha1.HTTPCheck) {
+		up <- chk.Name
+	}).Return(nil).AnyTimes()
+	cli.EXPECT().DeleteHTTPCheck(gomock.Any()).Do(func(chk v1alpha1.HTTPCheck) {
+		del <- chk.Name
+	}).Return(nil).AnyTimes()
+
+	ctrl := new(cli, zap.NewNop(), opts...)
+
+	factory := externalversions.NewSharedInformerFactory(cs, 0)
+	informer := factory.Heimdallr().V1alpha1().HTTPChecks().Informer()
+	informer.AddEventHandler(ctrl)
+	factory.Start(stop)
+	go ctrl.Run(1, stop)
+
+	return up, del
+}
+
+func expectName(t *testing.T, names <-chan string, want string) {
+	select {
+	case name := <-names:
+		require.Equal(t, want, name)
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for check %v", want)
+	}
+}
+
+func TestInformerWatchDroppedDelete(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	cs := fake.NewSimpleClientset(newInformerCheck("check", "1"))
+	watches := newWatchDropper(cs)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	updated, deleted := runInformer(mCtrl, cs, stop)
+	expectName(t, updated, "check")
+
+	// The deletion isn't reported by the watch, so the informer only notices the check is gone
+	// when it relists checks and hands the controller a tombstone.
+	err := cs.HeimdallrV1alpha1().HTTPChecks("default").Delete("check", &metav1.DeleteOptions{})
+	require.NoError(t, err)
+	watches.drop(t)
+
+	expectName(t, deleted, "check")
+}
+
+func TestInformerWatchDroppedRename(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	cs := fake.NewSimpleClientset(newInformerCheck("check", "1"))
+	watches := newWatchDropper(cs)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	updated, deleted := runInformer(mCtrl, cs, stop)
+	expectName(t, updated, "check")
+
+	// Renaming a check deletes it and creates a new one, neither of which the watch reports.
+	checks := cs.HeimdallrV1alpha1().HTTPChecks("default")
+	require.NoError(t, checks.Delete("check", &metav1.DeleteOptions{}))
+	_, err := checks.Create(newInformerCheck("renamed", "2"))
+	require.NoError(t, err)
+	watches.drop(t)
+
+	expectName(t, updated, "renamed")
+	expectName(t, deleted, "check")
+}
+
+func TestInformerMissedDeleteReconciled(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	cs := fake.NewSimpleClientset(newInformerCheck("check", "1"))
+	newWatchDropper(cs)
+
+	lister := clientsetLister{cs: cs}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	updated, deleted := runInformer(mCtrl, cs, stop,
+		WithReconciliation(lister, []string{"default"}, 100*time.Millisecond))
+	expectName(t, updated, "check")
+
+	// The watch is never dropped, so the informer doesn't notice the deletion, but the
+	// reconciler does.
+	err := cs.HeimdallrV1alpha1().HTTPChecks("default").Delete("check", &metav1.DeleteOptions{})
+	require.NoError(t, err)
+
+	expectName(t, deleted, "check")
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package controller
+
+import (
+	"fmt"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// MaintenanceHandler watches for maintenance windows and translates them into calls to Pingdom.
+// The checks a window selects are listed each time it's updated, and windows are updated again
+// whenever a check in their namespace changes, so that they include checks created after them.
+type MaintenanceHandler struct {
+	client MaintenanceClient
+	checks CheckLister
+	queue  *objectQueue
+	logger *zap.Logger
+}
+
+// NewMaintenanceHandler creates a new maintenance window handler.
+func NewMaintenanceHandler(client MaintenanceClient, checks CheckLister, logger *zap.Logger) *MaintenanceHandler {
+	h := &MaintenanceHandler{
+		client: client,
+		checks: checks,
+		logger: logger,
+	}
+	h.queue = newObjectQueue("maintenancewindows", h.update, h.delete, logger)
+	return h
+}
+
+// Run processes changes to maintenance windows until stop is closed.
+func (h *MaintenanceHandler) Run(stop <-chan struct{}) {
+	h.queue.run(stop)
+}
+
+// OnAdd handles new maintenance windows.
+func (h *MaintenanceHandler) OnAdd(obj interface{}) {
+	window, ok := obj.(*v1alpha1.MaintenanceWindow)
+	if !ok {
+		logUnexpected(h.logger, "OnAdd", obj)
+		return
+	}
+
+	h.queue.add(window)
+}
+
+// OnUpdate handles updated maintenance windows.
+func (h *MaintenanceHandler) OnUpdate(oldObj, newObj interface{}) {
+	window, ok := newObj.(*v1alpha1.MaintenanceWindow)
+	if !ok {
+		logUnexpected(h.logger, "OnUpdate", newObj)
+		return
+	}
+
+	h.queue.add(window)
+}
+
+// OnDelete handles deleted maintenance windows.
+func (h *MaintenanceHandler) OnDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	window, ok := obj.(*v1alpha1.MaintenanceWindow)
+	if !ok {
+		logUnexpected(h.logger, "OnDelete", obj)
+		return
+	}
+
+	h.queue.remove(window)
+}
+
+// CheckChanged updates the maintenance windows in the namespace of the check again, after the
+// check was created or deleted in Pingdom, or its labels changed.
+func (h *MaintenanceHandler) CheckChanged(check *v1alpha1.HTTPCheck) {
+	h.queue.requeue(func(obj interface{}) bool {
+		return obj.(*v1alpha1.MaintenanceWindow).Namespace == check.Namespace
+	})
+}
+
+func (h *MaintenanceHandler) update(obj interface{}) error {
+	window := obj.(*v1alpha1.MaintenanceWindow)
+	if err := h.sync(window); err != nil {
+		h.logger.Error(
+			"unexpected error encountered updating maintenance window",
+			zap.String("namespace", window.Namespace),
+			zap.String("name", window.Name),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (h *MaintenanceHandler) delete(obj interface{}) error {
+	window := obj.(*v1alpha1.MaintenanceWindow)
+	if err := h.client.DeleteMaintenanceWindow(*window); err != nil {
+		h.logger.Error(
+			"unexpected error encountered deleting maintenance window",
+			zap.String("namespace", window.Namespace),
+			zap.String("name", window.Name),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (h *MaintenanceHandler) sync(window *v1alpha1.MaintenanceWindow) error {
+	// A nil selector selects nothing, unlike an empty one which selects every check.
+	var checks []v1alpha1.HTTPCheck
+	if window.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(window.Spec.Selector)
+		if err != nil {
+			return fmt.Errorf("failed to parse selector: %v", err)
+		}
+
+		checks, err = h.checks.ListChecks(window.Namespace, selector.String())
+		if err != nil {
+			return fmt.Errorf("failed to list checks: %v", err)
+		}
+	}
+
+	return h.client.UpdateMaintenanceWindow(*window, checks)
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMaintenanceHandler(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	var (
+		window = v1alpha1.MaintenanceWindow{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deploy"},
+			Spec: v1alpha1.MaintenanceWindowSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+		}
+		checks = []v1alpha1.HTTPCheck{
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}},
+		}
+
+		cli    = NewMockMaintenanceClient(mCtrl)
+		lister = NewMockCheckLister(mCtrl)
+	)
+
+	gomock.InOrder(
+		lister.EXPECT().ListChecks("default", "app=web").Return(checks, nil),
+		cli.EXPECT().UpdateMaintenanceWindow(window, checks).Return(nil),
+		lister.EXPECT().ListChecks("default", "app=web").Return(nil, errors.New("forbidden")),
+		cli.EXPECT().DeleteMaintenanceWindow(window).Return(nil),
+	)
+
+	h := NewMaintenanceHandler(cli, lister, zap.NewNop())
+	h.OnAdd(&window)
+	h.queue.processNextItem()
+
+	// Failures are retried.
+	h.OnUpdate(&window, &window)
+	h.queue.processNextItem()
+	assert.Equal(t, 1, h.queue.NumRequeues("default/deploy"))
+
+	h.OnDelete(&window)
+	h.queue.processNextItem()
+
+	// Unexpected objects are ignored.
+	h.OnAdd(&v1alpha1.HTTPCheck{})
+}
+
+func TestMaintenanceHandlerCheckChanged(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	window := v1alpha1.MaintenanceWindow{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deploy"},
+	}
+
+	cli := NewMockMaintenanceClient(mCtrl)
+	cli.EXPECT().UpdateMaintenanceWindow(window, nil).Times(2).Return(nil)
+
+	h := NewMaintenanceHandler(cli, NewMockCheckLister(mCtrl), zap.NewNop())
+	h.OnAdd(&window)
+	h.queue.processNextItem()
+
+	// Windows are only updated again when a check in their namespace changes.
+	h.CheckChanged(&v1alpha1.HTTPCheck{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "web"}})
+	assert.Equal(t, 0, h.queue.Len())
+
+	h.CheckChanged(&v1alpha1.HTTPCheck{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}})
+	assert.Equal(t, 1, h.queue.Len())
+	h.queue.processNextItem()
+
+	// Deleted windows aren't updated again.
+	h.OnDelete(&window)
+	cli.EXPECT().DeleteMaintenanceWindow(window).Return(nil)
+	h.queue.processNextItem()
+	h.CheckChanged(&v1alpha1.HTTPCheck{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}})
+	assert.Equal(t, 0, h.queue.Len())
+}
+
+func TestMaintenanceHandlerWithoutSelector(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	window := v1alpha1.MaintenanceWindow{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deploy"},
+	}
+
+	// Without a selector no checks are listed or put into maintenance.
+	cli := NewMockMaintenanceClient(mCtrl)
+	cli.EXPECT().UpdateMaintenanceWindow(window, nil).Return(nil)
+
+	h := NewMaintenanceHandler(cli, NewMockCheckLister(mCtrl), zap.NewNop())
+	h.OnAdd(&window)
+	h.queue.processNextItem()
+}
+
+func TestControllerUpdatesMaintenanceWindows(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	var (
+		window = v1alpha1.MaintenanceWindow{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deploy"},
+		}
+		check = v1alpha1.HTTPCheck{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		}
+	)
+
+	mc := NewMockMaintenanceClient(mCtrl)
+	mc.EXPECT().UpdateMaintenanceWindow(window, nil).Return(nil)
+
+	windows := NewMaintenanceHandler(mc, NewMockCheckLister(mCtrl), zap.NewNop())
+	windows.queue.objects["default/deploy"] = &window
+
+	labeled := check.DeepCopy()
+	labeled.Labels = map[string]string{"app": "web"}
+
+	cli := NewMockPingdomClient(mCtrl)
+	cli.EXPECT().UpdateHTTPCheck(check).Times(2).Return(nil)
+	cli.EXPECT().UpdateHTTPCheck(*labeled).Return(nil)
+
+	// Windows are updated once a check they may select was created in Pingdom.
+	ctrl := new(cli, zap.NewNop(), WithMaintenance(windows))
+	ctrl.OnAdd(&check)
+	assert.Equal(t, 0, windows.queue.Len())
+	ctrl.processNextItem()
+	assert.Equal(t, 1, windows.queue.Len())
+	windows.queue.processNextItem()
+
+	// Resyncs of the check don't update them again, unlike changes to its labels.
+	ctrl.OnUpdate(&check, &check)
+	ctrl.processNextItem()
+	assert.Equal(t, 0, windows.queue.Len())
+
+	ctrl.OnUpdate(&check, labeled)
+	ctrl.processNextItem()
+	assert.Equal(t, 1, windows.queue.Len())
+}
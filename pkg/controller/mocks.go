@@ -7,52 +7,400 @@ package controller
 import (
 	gomock "github.com/golang/mock/gomock"
 	v1alpha1 "github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	pingdom "github.com/jeromefroe/heimdallr/pkg/pingdom"
 	reflect "reflect"
+	time "time"
 )
 
-// MockpingdomClient is a mock of pingdomClient interface
-type MockpingdomClient struct {
+// MockPingdomClient is a mock of PingdomClient interface
+type MockPingdomClient struct {
 	ctrl     *gomock.Controller
-	recorder *MockpingdomClientMockRecorder
+	recorder *MockPingdomClientMockRecorder
 }
 
-// MockpingdomClientMockRecorder is the mock recorder for MockpingdomClient
-type MockpingdomClientMockRecorder struct {
-	mock *MockpingdomClient
+// MockPingdomClientMockRecorder is the mock recorder for MockPingdomClient
+type MockPingdomClientMockRecorder struct {
+	mock *MockPingdomClient
 }
 
-// NewMockpingdomClient creates a new mock instance
-func NewMockpingdomClient(ctrl *gomock.Controller) *MockpingdomClient {
-	mock := &MockpingdomClient{ctrl: ctrl}
-	mock.recorder = &MockpingdomClientMockRecorder{mock}
+// NewMockPingdomClient creates a new mock instance
+func NewMockPingdomClient(ctrl *gomock.Controller) *MockPingdomClient {
+	mock := &MockPingdomClient{ctrl: ctrl}
+	mock.recorder = &MockPingdomClientMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use
-func (m *MockpingdomClient) EXPECT() *MockpingdomClientMockRecorder {
+func (m *MockPingdomClient) EXPECT() *MockPingdomClientMockRecorder {
 	return m.recorder
 }
 
 // UpdateHTTPCheck mocks base method
-func (m *MockpingdomClient) UpdateHTTPCheck(check v1alpha1.HTTPCheck) error {
+func (m *MockPingdomClient) UpdateHTTPCheck(check v1alpha1.HTTPCheck) error {
 	ret := m.ctrl.Call(m, "UpdateHTTPCheck", check)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateHTTPCheck indicates an expected call of UpdateHTTPCheck
-func (mr *MockpingdomClientMockRecorder) UpdateHTTPCheck(check interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHTTPCheck", reflect.TypeOf((*MockpingdomClient)(nil).UpdateHTTPCheck), check)
+func (mr *MockPingdomClientMockRecorder) UpdateHTTPCheck(check interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHTTPCheck", reflect.TypeOf((*MockPingdomClient)(nil).UpdateHTTPCheck), check)
 }
 
 // DeleteHTTPCheck mocks base method
-func (m *MockpingdomClient) DeleteHTTPCheck(check v1alpha1.HTTPCheck) error {
+func (m *MockPingdomClient) DeleteHTTPCheck(check v1alpha1.HTTPCheck) error {
 	ret := m.ctrl.Call(m, "DeleteHTTPCheck", check)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteHTTPCheck indicates an expected call of DeleteHTTPCheck
-func (mr *MockpingdomClientMockRecorder) DeleteHTTPCheck(check interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHTTPCheck", reflect.TypeOf((*MockpingdomClient)(nil).DeleteHTTPCheck), check)
+func (mr *MockPingdomClientMockRecorder) DeleteHTTPCheck(check interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHTTPCheck", reflect.TypeOf((*MockPingdomClient)(nil).DeleteHTTPCheck), check)
+}
+
+// MockOrphanClient is a mock of OrphanClient interface
+type MockOrphanClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrphanClientMockRecorder
+}
+
+// MockOrphanClientMockRecorder is the mock recorder for MockOrphanClient
+type MockOrphanClientMockRecorder struct {
+	mock *MockOrphanClient
+}
+
+// NewMockOrphanClient creates a new mock instance
+func NewMockOrphanClient(ctrl *gomock.Controller) *MockOrphanClient {
+	mock := &MockOrphanClient{ctrl: ctrl}
+	mock.recorder = &MockOrphanClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockOrphanClient) EXPECT() *MockOrphanClientMockRecorder {
+	return m.recorder
+}
+
+// DeleteOrphanedHTTPChecks mocks base method
+func (m *MockOrphanClient) DeleteOrphanedHTTPChecks(list func() ([]v1alpha1.HTTPCheck, error)) error {
+	ret := m.ctrl.Call(m, "DeleteOrphanedHTTPChecks", list)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrphanedHTTPChecks indicates an expected call of DeleteOrphanedHTTPChecks
+func (mr *MockOrphanClientMockRecorder) DeleteOrphanedHTTPChecks(list interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrphanedHTTPChecks", reflect.TypeOf((*MockOrphanClient)(nil).DeleteOrphanedHTTPChecks), list)
+}
+
+// MockAlertingClient is a mock of AlertingClient interface
+type MockAlertingClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlertingClientMockRecorder
+}
+
+// MockAlertingClientMockRecorder is the mock recorder for MockAlertingClient
+type MockAlertingClientMockRecorder struct {
+	mock *MockAlertingClient
+}
+
+// NewMockAlertingClient creates a new mock instance
+func NewMockAlertingClient(ctrl *gomock.Controller) *MockAlertingClient {
+	mock := &MockAlertingClient{ctrl: ctrl}
+	mock.recorder = &MockAlertingClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAlertingClient) EXPECT() *MockAlertingClientMockRecorder {
+	return m.recorder
+}
+
+// DeleteContact mocks base method
+func (m *MockAlertingClient) DeleteContact(contact v1alpha1.PingdomContact) error {
+	ret := m.ctrl.Call(m, "DeleteContact", contact)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteContact indicates an expected call of DeleteContact
+func (mr *MockAlertingClientMockRecorder) DeleteContact(contact interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteContact", reflect.TypeOf((*MockAlertingClient)(nil).DeleteContact), contact)
+}
+
+// DeleteTeam mocks base method
+func (m *MockAlertingClient) DeleteTeam(team v1alpha1.PingdomTeam) error {
+	ret := m.ctrl.Call(m, "DeleteTeam", team)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTeam indicates an expected call of DeleteTeam
+func (mr *MockAlertingClientMockRecorder) DeleteTeam(team interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTeam", reflect.TypeOf((*MockAlertingClient)(nil).DeleteTeam), team)
+}
+
+// UpdateContact mocks base method
+func (m *MockAlertingClient) UpdateContact(contact v1alpha1.PingdomContact) error {
+	ret := m.ctrl.Call(m, "UpdateContact", contact)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateContact indicates an expected call of UpdateContact
+func (mr *MockAlertingClientMockRecorder) UpdateContact(contact interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateContact", reflect.TypeOf((*MockAlertingClient)(nil).UpdateContact), contact)
+}
+
+// UpdateTeam mocks base method
+func (m *MockAlertingClient) UpdateTeam(team v1alpha1.PingdomTeam) error {
+	ret := m.ctrl.Call(m, "UpdateTeam", team)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTeam indicates an expected call of UpdateTeam
+func (mr *MockAlertingClientMockRecorder) UpdateTeam(team interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTeam", reflect.TypeOf((*MockAlertingClient)(nil).UpdateTeam), team)
+}
+
+// MockMaintenanceClient is a mock of MaintenanceClient interface
+type MockMaintenanceClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockMaintenanceClientMockRecorder
+}
+
+// MockMaintenanceClientMockRecorder is the mock recorder for MockMaintenanceClient
+type MockMaintenanceClientMockRecorder struct {
+	mock *MockMaintenanceClient
+}
+
+// NewMockMaintenanceClient creates a new mock instance
+func NewMockMaintenanceClient(ctrl *gomock.Controller) *MockMaintenanceClient {
+	mock := &MockMaintenanceClient{ctrl: ctrl}
+	mock.recorder = &MockMaintenanceClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMaintenanceClient) EXPECT() *MockMaintenanceClientMockRecorder {
+	return m.recorder
+}
+
+// UpdateMaintenanceWindow mocks base method
+func (m *MockMaintenanceClient) UpdateMaintenanceWindow(window v1alpha1.MaintenanceWindow, checks []v1alpha1.HTTPCheck) error {
+	ret := m.ctrl.Call(m, "UpdateMaintenanceWindow", window, checks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMaintenanceWindow indicates an expected call of UpdateMaintenanceWindow
+func (mr *MockMaintenanceClientMockRecorder) UpdateMaintenanceWindow(window, checks interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMaintenanceWindow", reflect.TypeOf((*MockMaintenanceClient)(nil).UpdateMaintenanceWindow), window, checks)
+}
+
+// DeleteMaintenanceWindow mocks base method
+func (m *MockMaintenanceClient) DeleteMaintenanceWindow(window v1alpha1.MaintenanceWindow) error {
+	ret := m.ctrl.Call(m, "DeleteMaintenanceWindow", window)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMaintenanceWindow indicates an expected call of DeleteMaintenanceWindow
+func (mr *MockMaintenanceClientMockRecorder) DeleteMaintenanceWindow(window interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMaintenanceWindow", reflect.TypeOf((*MockMaintenanceClient)(nil).DeleteMaintenanceWindow), window)
+}
+
+// MockStateClient is a mock of StateClient interface
+type MockStateClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockStateClientMockRecorder
+}
+
+// MockStateClientMockRecorder is the mock recorder for MockStateClient
+type MockStateClientMockRecorder struct {
+	mock *MockStateClient
+}
+
+// NewMockStateClient creates a new mock instance
+func NewMockStateClient(ctrl *gomock.Controller) *MockStateClient {
+	mock := &MockStateClient{ctrl: ctrl}
+	mock.recorder = &MockStateClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStateClient) EXPECT() *MockStateClientMockRecorder {
+	return m.recorder
+}
+
+// CheckStates mocks base method
+func (m *MockStateClient) CheckStates(checks []v1alpha1.HTTPCheck) (map[string]pingdom.CheckState, error) {
+	ret := m.ctrl.Call(m, "CheckStates", checks)
+	ret0, _ := ret[0].(map[string]pingdom.CheckState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckStates indicates an expected call of CheckStates
+func (mr *MockStateClientMockRecorder) CheckStates(checks interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckStates", reflect.TypeOf((*MockStateClient)(nil).CheckStates), checks)
+}
+
+// Uptimes mocks base method
+func (m *MockStateClient) Uptimes(check v1alpha1.HTTPCheck, windows []time.Duration) ([]pingdom.Uptime, error) {
+	ret := m.ctrl.Call(m, "Uptimes", check, windows)
+	ret0, _ := ret[0].([]pingdom.Uptime)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Uptimes indicates an expected call of Uptimes
+func (mr *MockStateClientMockRecorder) Uptimes(check, windows interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Uptimes", reflect.TypeOf((*MockStateClient)(nil).Uptimes), check, windows)
+}
+
+// MockCheckLister is a mock of CheckLister interface
+type MockCheckLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockCheckListerMockRecorder
+}
+
+// MockCheckListerMockRecorder is the mock recorder for MockCheckLister
+type MockCheckListerMockRecorder struct {
+	mock *MockCheckLister
+}
+
+// NewMockCheckLister creates a new mock instance
+func NewMockCheckLister(ctrl *gomock.Controller) *MockCheckLister {
+	mock := &MockCheckLister{ctrl: ctrl}
+	mock.recorder = &MockCheckListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCheckLister) EXPECT() *MockCheckListerMockRecorder {
+	return m.recorder
+}
+
+// ListChecks mocks base method
+func (m *MockCheckLister) ListChecks(namespace, selector string) ([]v1alpha1.HTTPCheck, error) {
+	ret := m.ctrl.Call(m, "ListChecks", namespace, selector)
+	ret0, _ := ret[0].([]v1alpha1.HTTPCheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChecks indicates an expected call of ListChecks
+func (mr *MockCheckListerMockRecorder) ListChecks(namespace, selector interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChecks", reflect.TypeOf((*MockCheckLister)(nil).ListChecks), namespace, selector)
+}
+
+// MockRolloutGetter is a mock of RolloutGetter interface
+type MockRolloutGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockRolloutGetterMockRecorder
+}
+
+// MockRolloutGetterMockRecorder is the mock recorder for MockRolloutGetter
+type MockRolloutGetterMockRecorder struct {
+	mock *MockRolloutGetter
+}
+
+// NewMockRolloutGetter creates a new mock instance
+func NewMockRolloutGetter(ctrl *gomock.Controller) *MockRolloutGetter {
+	mock := &MockRolloutGetter{ctrl: ctrl}
+	mock.recorder = &MockRolloutGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRolloutGetter) EXPECT() *MockRolloutGetterMockRecorder {
+	return m.recorder
+}
+
+// RolloutInProgress mocks base method
+func (m *MockRolloutGetter) RolloutInProgress(namespace, name string) (bool, error) {
+	ret := m.ctrl.Call(m, "RolloutInProgress", namespace, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RolloutInProgress indicates an expected call of RolloutInProgress
+func (mr *MockRolloutGetterMockRecorder) RolloutInProgress(namespace, name interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RolloutInProgress", reflect.TypeOf((*MockRolloutGetter)(nil).RolloutInProgress), namespace, name)
+}
+
+// MockCredentialsGetter is a mock of CredentialsGetter interface
+type MockCredentialsGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockCredentialsGetterMockRecorder
+}
+
+// MockCredentialsGetterMockRecorder is the mock recorder for MockCredentialsGetter
+type MockCredentialsGetterMockRecorder struct {
+	mock *MockCredentialsGetter
+}
+
+// NewMockCredentialsGetter creates a new mock instance
+func NewMockCredentialsGetter(ctrl *gomock.Controller) *MockCredentialsGetter {
+	mock := &MockCredentialsGetter{ctrl: ctrl}
+	mock.recorder = &MockCredentialsGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCredentialsGetter) EXPECT() *MockCredentialsGetterMockRecorder {
+	return m.recorder
+}
+
+// GetCredentials mocks base method
+func (m *MockCredentialsGetter) GetCredentials(namespace, name string) (pingdom.Credentials, string, error) {
+	ret := m.ctrl.Call(m, "GetCredentials", namespace, name)
+	ret0, _ := ret[0].(pingdom.Credentials)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetCredentials indicates an expected call of GetCredentials
+func (mr *MockCredentialsGetterMockRecorder) GetCredentials(namespace, name interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCredentials", reflect.TypeOf((*MockCredentialsGetter)(nil).GetCredentials), namespace, name)
+}
+
+// MockStatusUpdater is a mock of StatusUpdater interface
+type MockStatusUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatusUpdaterMockRecorder
+}
+
+// MockStatusUpdaterMockRecorder is the mock recorder for MockStatusUpdater
+type MockStatusUpdaterMockRecorder struct {
+	mock *MockStatusUpdater
+}
+
+// NewMockStatusUpdater creates a new mock instance
+func NewMockStatusUpdater(ctrl *gomock.Controller) *MockStatusUpdater {
+	mock := &MockStatusUpdater{ctrl: ctrl}
+	mock.recorder = &MockStatusUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStatusUpdater) EXPECT() *MockStatusUpdaterMockRecorder {
+	return m.recorder
+}
+
+// UpdateStatus mocks base method
+func (m *MockStatusUpdater) UpdateStatus(check *v1alpha1.HTTPCheck) error {
+	ret := m.ctrl.Call(m, "UpdateStatus", check)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus
+func (mr *MockStatusUpdaterMockRecorder) UpdateStatus(check interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockStatusUpdater)(nil).UpdateStatus), check)
 }
@@ -0,0 +1,135 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package controller
+
+import (
+	"fmt"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"go.uber.org/zap"
+)
+
+// PauseDuringRolloutAnnotation is the annotation naming a Deployment, in the namespace of a
+// check, during whose rollouts the check is paused.
+const PauseDuringRolloutAnnotation = "heimdallr.froe.io/pause-during-rollout"
+
+// Reasons for the Paused condition of a check.
+const (
+	reasonPausedBySpec      = "PausedBySpec"
+	reasonRolloutInProgress = "RolloutInProgress"
+	reasonRolloutComplete   = "RolloutComplete"
+	reasonRolloutUnknown    = "RolloutUnknown"
+	reasonNotPaused         = "NotPaused"
+)
+
+// withPause returns the check to sync with Pingdom, which is paused if its spec says so or the
+// Deployment named by its PauseDuringRolloutAnnotation is rolling out, and records whether it's
+// paused in its Paused condition.
+func (c *Controller) withPause(chk *v1alpha1.HTTPCheck) v1alpha1.HTTPCheck {
+	paused, cond, ok := c.pauseCondition(chk)
+	if ok {
+		setCondition(&chk.Status, cond)
+	}
+
+	desired := *chk
+	desired.Spec.Paused = paused
+	return desired
+}
+
+// pauseCondition returns whether the check should be paused and its Paused condition. Checks
+// which have never been paused and can't be paused by a rollout don't get a condition.
+func (c *Controller) pauseCondition(chk *v1alpha1.HTTPCheck) (bool, v1alpha1.HTTPCheckCondition, bool) {
+	cond := v1alpha1.HTTPCheckCondition{
+		Type:   v1alpha1.HTTPCheckPaused,
+		Status: v1alpha1.ConditionFalse,
+		Reason: reasonNotPaused,
+	}
+
+	if chk.Spec.Paused {
+		cond.Status = v1alpha1.ConditionTrue
+		cond.Reason = reasonPausedBySpec
+		return true, cond, true
+	}
+
+	deployment := chk.Annotations[PauseDuringRolloutAnnotation]
+	if deployment == "" || c.rollouts == nil {
+		return false, cond, hasCondition(chk.Status, v1alpha1.HTTPCheckPaused)
+	}
+
+	inProgress, err := c.rollouts.RolloutInProgress(chk.Namespace, deployment)
+	switch {
+	case err != nil:
+		// Checks aren't left paused when the state of the rollout is unknown, since a paused
+		// check can't alert about the outage that might be the reason for it.
+		c.logger.Warn(
+			"unable to get rollout state of deployment, not pausing check",
+			zap.String("name", chk.Name),
+			zap.String("deployment", deployment),
+			zap.Error(err),
+		)
+		cond.Reason = reasonRolloutUnknown
+		cond.Message = fmt.Sprintf("failed to get rollout state of deployment %v: %v", deployment, err)
+	case inProgress:
+		cond.Status = v1alpha1.ConditionTrue
+		cond.Reason = reasonRolloutInProgress
+		cond.Message = fmt.Sprintf("deployment %v is rolling out", deployment)
+	default:
+		cond.Reason = reasonRolloutComplete
+		cond.Message = fmt.Sprintf("deployment %v is not rolling out", deployment)
+	}
+	return inProgress, cond, true
+}
+
+// RolloutChanged syncs the checks which are paused during the rollouts of the given Deployment
+// after a rollout of it starts or completes.
+func (c *Controller) RolloutChanged(namespace, deployment string) {
+	if c.checks == nil {
+		return
+	}
+
+	checks, err := c.checks.ListChecks(namespace, "")
+	if err != nil {
+		c.logger.Error(
+			"unable to list checks paused during rollout",
+			zap.String("namespace", namespace),
+			zap.String("deployment", deployment),
+			zap.Error(err),
+		)
+		return
+	}
+
+	for i := range checks {
+		chk := &checks[i]
+		if chk.Annotations[PauseDuringRolloutAnnotation] == deployment {
+			c.enqueue(eventUpdate, chk)
+		}
+	}
+}
+
+func hasCondition(status v1alpha1.HTTPCheckStatus, typ v1alpha1.HTTPCheckConditionType) bool {
+	for _, cond := range status.Conditions {
+		if cond.Type == typ {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newRolloutCheck(name, deployment string) v1alpha1.HTTPCheck {
+	return v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        name,
+			Annotations: map[string]string{PauseDuringRolloutAnnotation: deployment},
+		},
+	}
+}
+
+// expectPaused asserts whether the check synced with Pingdom is paused.
+func expectPaused(t *testing.T, paused bool) func(v1alpha1.HTTPCheck) {
+	return func(chk v1alpha1.HTTPCheck) {
+		assert.Equal(t, paused, chk.Spec.Paused)
+	}
+}
+
+func TestPausedBySpec(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{Name: "check"},
+		Spec:       v1alpha1.HTTPCheckSpec{Paused: true},
+	}
+
+	cli := NewMockPingdomClient(mCtrl)
+	cli.EXPECT().UpdateHTTPCheck(gomock.Any()).Do(expectPaused(t, true)).Return(nil)
+
+	status := statusRecorder{}
+	ctrl := new(cli, zap.NewNop(), WithStatusUpdater(status))
+	ctrl.OnAdd(&check)
+	ctrl.processNextItem()
+
+	require.Len(t, status["check"].Conditions, 2)
+	assert.Equal(t, v1alpha1.HTTPCheckPaused, status["check"].Conditions[0].Type)
+	assert.Equal(t, v1alpha1.ConditionTrue, status["check"].Conditions[0].Status)
+	assert.Equal(t, reasonPausedBySpec, status["check"].Conditions[0].Reason)
+}
+
+func TestPausedDuringRollout(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	var (
+		check    = newRolloutCheck("check", "web")
+		cli      = NewMockPingdomClient(mCtrl)
+		rollouts = NewMockRolloutGetter(mCtrl)
+	)
+
+	gomock.InOrder(
+		rollouts.EXPECT().RolloutInProgress("default", "web").Return(true, nil),
+		cli.EXPECT().UpdateHTTPCheck(gomock.Any()).Do(expectPaused(t, true)).Return(nil),
+		rollouts.EXPECT().RolloutInProgress("default", "web").Return(false, nil),
+		cli.EXPECT().UpdateHTTPCheck(gomock.Any()).Do(expectPaused(t, false)).Return(nil),
+	)
+
+	status := statusRecorder{}
+	ctrl := new(cli, zap.NewNop(), WithRollouts(rollouts, nil), WithStatusUpdater(status))
+	ctrl.OnAdd(&check)
+	ctrl.processNextItem()
+
+	// The check itself isn't paused, only the check synced with Pingdom.
+	assert.False(t, check.Spec.Paused)
+	cond := status["check"].Conditions[0]
+	assert.Equal(t, v1alpha1.HTTPCheckPaused, cond.Type)
+	assert.Equal(t, v1alpha1.ConditionTrue, cond.Status)
+	assert.Equal(t, reasonRolloutInProgress, cond.Reason)
+
+	// Resuming the check once the rollout completes is recorded as a transition.
+	updated := check.DeepCopy()
+	updated.Spec.Hostname = "foo.io"
+	updated.Status = status["check"]
+	updated.Status.Conditions[0].LastTransitionTime = metav1.Unix(0, 0)
+	ctrl.OnUpdate(&check, updated)
+	ctrl.processNextItem()
+
+	cond = status["check"].Conditions[0]
+	assert.Equal(t, v1alpha1.ConditionFalse, cond.Status)
+	assert.Equal(t, reasonRolloutComplete, cond.Reason)
+	assert.NotEqual(t, metav1.Unix(0, 0), cond.LastTransitionTime)
+}
+
+func TestPausedDuringRolloutUnknown(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	var (
+		check    = newRolloutCheck("check", "web")
+		cli      = NewMockPingdomClient(mCtrl)
+		rollouts = NewMockRolloutGetter(mCtrl)
+	)
+
+	// Checks aren't paused if the rollout state can't be determined.
+	rollouts.EXPECT().RolloutInProgress("default", "web").Return(false, errors.New("not found"))
+	cli.EXPECT().UpdateHTTPCheck(gomock.Any()).Do(expectPaused(t, false)).Return(nil)
+
+	status := statusRecorder{}
+	ctrl := new(cli, zap.NewNop(), WithRollouts(rollouts, nil), WithStatusUpdater(status))
+	ctrl.OnAdd(&check)
+	ctrl.processNextItem()
+
+	assert.Equal(t, reasonRolloutUnknown, status["check"].Conditions[0].Reason)
+}
+
+func TestRolloutChanged(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	var (
+		checks = []v1alpha1.HTTPCheck{
+			newRolloutCheck("web", "web"),
+			newRolloutCheck("api", "api"),
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "plain"}},
+		}
+		lister = NewMockCheckLister(mCtrl)
+	)
+	lister.EXPECT().ListChecks("default", "").Return(checks, nil)
+
+	ctrl := new(NewMockPingdomClient(mCtrl), zap.NewNop(), WithRollouts(NewMockRolloutGetter(mCtrl), lister))
+	ctrl.RolloutChanged("default", "web")
+
+	// Only the check paused during the rollouts of the deployment is synced.
+	assert.Equal(t, 1, ctrl.queue.Len())
+	assert.Contains(t, ctrl.queue.pending, "default/web")
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	"github.com/jeromefroe/heimdallr/pkg/pingdom"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StatusPoller periodically copies the state of checks in Pingdom into their status. Only the
+// checks of the account heimdallr was started with are polled, not those which reference the
+// credentials of another account.
+type StatusPoller struct {
+	client  StateClient
+	checks  CheckLister
+	status  StatusUpdater
+	windows []time.Duration
+	logger  *zap.Logger
+
+	// recorded are the names of the checks in each namespace with uptime gauges, so that the
+	// gauges of deleted checks can be removed.
+	recorded map[string]map[string]bool
+}
+
+// PollerOption configures a StatusPoller.
+type PollerOption func(*StatusPoller)
+
+// WithUptimeWindows configures the poller to record the uptime of checks over the given windows
+// in their status and as gauges.
+func WithUptimeWindows(windows ...time.Duration) PollerOption {
+	return func(p *StatusPoller) {
+		p.windows = windows
+	}
+}
+
+// NewStatusPoller creates a new status poller.
+func NewStatusPoller(
+	client StateClient,
+	checks CheckLister,
+	status StatusUpdater,
+	logger *zap.Logger,
+	opts ...PollerOption,
+) *StatusPoller {
+	p := &StatusPoller{
+		client:   client,
+		checks:   checks,
+		status:   status,
+		logger:   logger,
+		recorded: make(map[string]map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Run polls the state of the checks in the given namespaces at the given interval until stop is
+// closed.
+func (p *StatusPoller) Run(namespaces []string, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, ns := range namespaces {
+			if err := p.poll(ns); err != nil {
+				p.logger.Error("unable to poll state of checks", zap.String("namespace", ns), zap.Error(err))
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// poll updates the status of the checks in a namespace whose state in Pingdom changed.
+func (p *StatusPoller) poll(namespace string) error {
+	list, err := p.checks.ListChecks(namespace, "")
+	if err != nil {
+		return fmt.Errorf("failed to list checks: %v", err)
+	}
+
+	var checks []v1alpha1.HTTPCheck
+	for _, chk := range list {
+		if chk.Spec.CredentialsRef == nil {
+			checks = append(checks, chk)
+		}
+	}
+	var states map[string]pingdom.CheckState
+	if len(checks) > 0 {
+		if states, err = p.client.CheckStates(checks); err != nil {
+			return err
+		}
+	}
+
+	recorded := make(map[string]bool)
+	for i := range checks {
+		chk := &checks[i]
+		state, ok := states[chk.Namespace+"/"+chk.Name]
+		if !ok {
+			continue
+		}
+
+		changed := applyState(&chk.Status, state)
+		if len(p.windows) > 0 {
+			if p.pollUptime(chk) {
+				changed = true
+			}
+			recorded[chk.Name] = true
+		}
+		if !changed {
+			continue
+		}
+		if err := p.status.UpdateStatus(chk); err != nil {
+			p.logger.Warn("unable to update status of check", zap.String("name", chk.Name), zap.Error(err))
+		}
+	}
+
+	for name := range p.recorded[namespace] {
+		if !recorded[name] {
+			for _, window := range p.windows {
+				uptime.DeleteLabelValues(namespace, name, formatWindow(window))
+			}
+		}
+	}
+	p.recorded[namespace] = recorded
+	return nil
+}
+
+// pollUptime copies the uptime of a check into its status and gauges, returning whether its
+// status changed. The previous uptime is kept if the current one can't be read.
+func (p *StatusPoller) pollUptime(chk *v1alpha1.HTTPCheck) bool {
+	uptimes, err := p.client.Uptimes(*chk, p.windows)
+	if err != nil {
+		p.logger.Warn("unable to get uptime of check", zap.String("name", chk.Name), zap.Error(err))
+		return false
+	}
+
+	statuses := make([]v1alpha1.UptimeStatus, len(uptimes))
+	for i, u := range uptimes {
+		window := formatWindow(u.Window)
+		statuses[i] = v1alpha1.UptimeStatus{
+			Window:  window,
+			Percent: strconv.FormatFloat(u.Percent, 'f', 3, 64),
+		}
+		uptime.WithLabelValues(chk.Namespace, chk.Name, window).Set(u.Percent)
+	}
+
+	if len(statuses) == 0 {
+		statuses = nil
+	}
+	if reflect.DeepEqual(chk.Status.Uptime, statuses) {
+		return false
+	}
+	chk.Status.Uptime = statuses
+	return true
+}
+
+// applyState copies the state of a check in Pingdom into its status, returning whether the
+// status changed.
+func applyState(status *v1alpha1.HTTPCheckStatus, state pingdom.CheckState) bool {
+	var (
+		lastTest     = optionalTime(state.LastTestTime)
+		lastError    = optionalTime(state.LastErrorTime)
+		responseTime = int64(state.LastResponseTime / time.Millisecond)
+	)
+
+	if status.PingdomStatus == state.Status &&
+		sameTime(status.LastTestTime, lastTest) &&
+		sameTime(status.LastErrorTime, lastError) &&
+		status.LastResponseTime == responseTime {
+		return false
+	}
+
+	status.PingdomStatus = state.Status
+	status.LastTestTime = lastTest
+	status.LastErrorTime = lastError
+	status.LastResponseTime = responseTime
+	return true
+}
+
+// formatWindow formats a window in days if it's a whole number of them, as 7d rather than 168h.
+func formatWindow(window time.Duration) string {
+	const day = 24 * time.Hour
+	if window >= day && window%day == 0 {
+		return fmt.Sprintf("%dd", window/day)
+	}
+	return window.String()
+}
+
+func optionalTime(t time.Time) *metav1.Time {
+	if t.IsZero() {
+		return nil
+	}
+	mt := metav1.NewTime(t)
+	return &mt
+}
+
+// sameTime compares times to the second, the precision with which they're serialized.
+func sameTime(a, b *metav1.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Unix() == b.Unix()
+}
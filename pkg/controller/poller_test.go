@@ -0,0 +1,167 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	"github.com/jeromefroe/heimdallr/pkg/pingdom"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStatusPoller(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	var (
+		lastTest  = time.Unix(1538431200, 0)
+		unchanged = metav1.NewTime(lastTest)
+
+		checks = []v1alpha1.HTTPCheck{
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "down"}},
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "up"},
+				Status: v1alpha1.HTTPCheckStatus{
+					PingdomStatus:    "up",
+					LastTestTime:     &unchanged,
+					LastResponseTime: 100,
+				},
+			},
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "missing"}},
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "other-account"},
+				Spec: v1alpha1.HTTPCheckSpec{
+					CredentialsRef: &v1alpha1.CredentialsReference{SecretName: "other"},
+				},
+			},
+		}
+
+		lister = NewMockCheckLister(mCtrl)
+		cli    = NewMockStateClient(mCtrl)
+		status = NewMockStatusUpdater(mCtrl)
+	)
+
+	lister.EXPECT().ListChecks("web", "").Return(checks, nil)
+	cli.EXPECT().CheckStates(checks[:3]).Return(map[string]pingdom.CheckState{
+		"web/down": {
+			Status:           "down",
+			LastTestTime:     lastTest,
+			LastErrorTime:    lastTest,
+			LastResponseTime: 5 * time.Second,
+		},
+		"web/up": {
+			Status:           "up",
+			LastTestTime:     lastTest,
+			LastResponseTime: 100 * time.Millisecond,
+		},
+	}, nil)
+
+	// Only the status of the check whose state changed is updated.
+	status.EXPECT().UpdateStatus(gomock.Any()).Do(func(chk *v1alpha1.HTTPCheck) {
+		assert.Equal(t, "down", chk.Name)
+		assert.Equal(t, "down", chk.Status.PingdomStatus)
+		assert.Equal(t, lastTest.Unix(), chk.Status.LastTestTime.Unix())
+		assert.Equal(t, lastTest.Unix(), chk.Status.LastErrorTime.Unix())
+		assert.Equal(t, int64(5000), chk.Status.LastResponseTime)
+	}).Return(nil)
+
+	p := NewStatusPoller(cli, lister, status, zap.NewNop())
+	require.NoError(t, p.poll("web"))
+}
+
+func TestStatusPollerUptime(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	var (
+		day     = 24 * time.Hour
+		windows = []time.Duration{day, 7 * day}
+		check   = v1alpha1.HTTPCheck{ObjectMeta: metav1.ObjectMeta{Namespace: "uptime", Name: "foo"}}
+
+		lister = NewMockCheckLister(mCtrl)
+		cli    = NewMockStateClient(mCtrl)
+		status = NewMockStatusUpdater(mCtrl)
+	)
+
+	gomock.InOrder(
+		lister.EXPECT().ListChecks("uptime", "").Return([]v1alpha1.HTTPCheck{check}, nil),
+		cli.EXPECT().CheckStates(gomock.Any()).Return(map[string]pingdom.CheckState{
+			"uptime/foo": {Status: "up"},
+		}, nil),
+		cli.EXPECT().Uptimes(gomock.Any(), windows).Return([]pingdom.Uptime{
+			{Window: day, Percent: 99.9},
+			{Window: 7 * day, Percent: 99.95},
+		}, nil),
+		status.EXPECT().UpdateStatus(gomock.Any()).Do(func(chk *v1alpha1.HTTPCheck) {
+			assert.Equal(t, []v1alpha1.UptimeStatus{
+				{Window: "1d", Percent: "99.900"},
+				{Window: "7d", Percent: "99.950"},
+			}, chk.Status.Uptime)
+		}).Return(nil),
+
+		// The gauges of checks which no longer exist are removed.
+		lister.EXPECT().ListChecks("uptime", "").Return(nil, nil),
+	)
+
+	p := NewStatusPoller(cli, lister, status, zap.NewNop(), WithUptimeWindows(windows...))
+	require.NoError(t, p.poll("uptime"))
+	assert.Equal(t, 99.95, testutil.ToFloat64(uptime.WithLabelValues("uptime", "foo", "7d")))
+
+	require.NoError(t, p.poll("uptime"))
+	assert.False(t, uptime.DeleteLabelValues("uptime", "foo", "7d"), "expected gauge to be removed")
+}
+
+func TestFormatWindow(t *testing.T) {
+	assert.Equal(t, "1d", formatWindow(24*time.Hour))
+	assert.Equal(t, "30d", formatWindow(720*time.Hour))
+	assert.Equal(t, "12h0m0s", formatWindow(12*time.Hour))
+	assert.Equal(t, "36h0m0s", formatWindow(36*time.Hour))
+}
+
+func TestStatusPollerRun(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	var (
+		lister = NewMockCheckLister(mCtrl)
+		stop   = make(chan struct{})
+		done   = make(chan struct{})
+	)
+
+	// The checks are polled immediately, and in each namespace.
+	lister.EXPECT().ListChecks("web", "").Return(nil, nil)
+	lister.EXPECT().ListChecks("api", "").Do(func(string, string) { close(stop) }).Return(nil, nil)
+
+	p := NewStatusPoller(NewMockStateClient(mCtrl), lister, NewMockStatusUpdater(mCtrl), zap.NewNop())
+	go func() {
+		p.Run([]string{"web", "api"}, time.Hour, stop)
+		close(done)
+	}()
+	<-done
+}
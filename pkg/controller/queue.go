@@ -0,0 +1,249 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package controller
+
+import (
+	"sync"
+
+	"github.com/jeromefroe/heimdallr/pkg/pingdom"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// maxRetries is the number of times a change is retried before it's dropped from the queue.
+// Permanent failures, such as invalid checks, aren't retried.
+const maxRetries = 5
+
+// shouldRetry returns whether an item of the queue which failed with the given error should be
+// retried. Items whose credentials were rejected are retried with a backoff until they succeed,
+// since they'd otherwise stay out of sync after the credentials are fixed, such as once a key
+// being rotated is reloaded, unless a resync is configured.
+func shouldRetry(queue workqueue.RateLimitingInterface, item interface{}, err error) bool {
+	return pingdom.IsUnauthorized(err) || queue.NumRequeues(item) < maxRetries
+}
+
+// changeQueue queues the latest change to each object by key, so that changes are made to
+// Pingdom off the informer's goroutine. Only the latest change to an object is processed if it
+// changes multiple times before it's processed, and changes which fail are retried with a backoff.
+type changeQueue struct {
+	workqueue.RateLimitingInterface
+	kind   string
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	pending map[string]interface{}
+}
+
+func newChangeQueue(kind string, logger *zap.Logger) *changeQueue {
+	return &changeQueue{
+		RateLimitingInterface: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), kind),
+		kind:                  kind,
+		logger:                logger,
+		pending:               make(map[string]interface{}),
+	}
+}
+
+// enqueue records the latest change to the object with the given key and queues it.
+func (q *changeQueue) enqueue(key string, change interface{}) {
+	q.mu.Lock()
+	q.pending[key] = change
+	q.mu.Unlock()
+
+	q.Add(key)
+}
+
+// enqueueIdle queues the change unless a change to the object with the given key is already
+// pending, and returns whether it was queued.
+func (q *changeQueue) enqueueIdle(key string, change interface{}) bool {
+	q.mu.Lock()
+	if _, ok := q.pending[key]; ok {
+		q.mu.Unlock()
+		return false
+	}
+	q.pending[key] = change
+	q.mu.Unlock()
+
+	q.Add(key)
+	return true
+}
+
+// process processes the next change in the queue with the given function, returning false once
+// the queue has been shut down. Changes which fail are retried unless the failure is permanent,
+// or they already failed too many times.
+func (q *changeQueue) process(handle func(change interface{}) error) bool {
+	item, shutdown := q.Get()
+	if shutdown {
+		return false
+	}
+	defer q.Done(item)
+
+	key := item.(string)
+
+	q.mu.Lock()
+	change, ok := q.pending[key]
+	delete(q.pending, key)
+	q.mu.Unlock()
+
+	if !ok {
+		// The change was already processed by an earlier item for the same object.
+		q.Forget(item)
+		return true
+	}
+
+	switch err := handle(change); {
+	case err == nil:
+	case pingdom.IsPermanent(err):
+		// Retrying won't help until the object changes.
+		q.logger.Error(
+			"dropping change after permanent failure",
+			zap.String("kind", q.kind), zap.String("key", key), zap.Error(err),
+		)
+	case shouldRetry(q, item, err):
+		q.mu.Lock()
+		if _, newer := q.pending[key]; !newer {
+			q.pending[key] = change
+		}
+		q.mu.Unlock()
+
+		q.AddRateLimited(item)
+		return true
+	default:
+		q.logger.Error(
+			"dropping change after too many failures",
+			zap.String("kind", q.kind), zap.String("key", key), zap.Error(err),
+		)
+	}
+
+	q.Forget(item)
+	return true
+}
+
+// objectQueue queues changes to Pingdom contacts, teams or maintenance windows like changes to
+// checks, and keeps the objects which exist so that they can be updated again when an object
+// they depend on changes.
+type objectQueue struct {
+	*changeQueue
+	update func(obj interface{}) error
+	delete func(obj interface{}) error
+
+	mu      sync.Mutex
+	objects map[string]interface{}
+}
+
+// objectEvent is the latest change to an object which hasn't been processed yet.
+type objectEvent struct {
+	obj     interface{}
+	deleted bool
+}
+
+func newObjectQueue(kind string, update, delete func(obj interface{}) error, logger *zap.Logger) *objectQueue {
+	return &objectQueue{
+		changeQueue: newChangeQueue(kind, logger),
+		update:      update,
+		delete:      delete,
+		objects:     make(map[string]interface{}),
+	}
+}
+
+// run processes changes until stop is closed.
+func (q *objectQueue) run(stop <-chan struct{}) {
+	defer q.ShutDown()
+
+	go func() {
+		for q.processNextItem() {
+		}
+	}()
+
+	<-stop
+}
+
+// add queues the object to be created or updated in Pingdom.
+func (q *objectQueue) add(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		q.logger.Error("unable to get key of object", zap.String("kind", q.kind), zap.Error(err))
+		return
+	}
+
+	q.mu.Lock()
+	q.objects[key] = obj
+	q.mu.Unlock()
+
+	q.enqueue(key, objectEvent{obj: obj})
+}
+
+// remove queues the object to be deleted from Pingdom.
+func (q *objectQueue) remove(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		q.logger.Error("unable to get key of object", zap.String("kind", q.kind), zap.Error(err))
+		return
+	}
+
+	q.mu.Lock()
+	delete(q.objects, key)
+	q.mu.Unlock()
+
+	q.enqueue(key, objectEvent{obj: obj, deleted: true})
+}
+
+// requeue queues the objects which exist and match the given function to be updated again, such
+// as after an object they depend on changed. Objects with a pending change aren't queued again.
+func (q *objectQueue) requeue(match func(obj interface{}) bool) {
+	q.mu.Lock()
+	objects := make(map[string]interface{})
+	for key, obj := range q.objects {
+		if match(obj) {
+			objects[key] = obj
+		}
+	}
+	q.mu.Unlock()
+
+	for key, obj := range objects {
+		q.enqueueIdle(key, objectEvent{obj: obj})
+	}
+}
+
+// processNextItem processes the next object in the queue, returning false once the queue has been
+// shut down.
+func (q *objectQueue) processNextItem() bool {
+	return q.process(q.handle)
+}
+
+func (q *objectQueue) handle(change interface{}) error {
+	ev := change.(objectEvent)
+
+	var err error
+	if ev.deleted {
+		err = q.delete(ev.obj)
+	} else {
+		err = q.update(ev.obj)
+	}
+
+	if _, ok := err.(*pingdom.UnresolvedReferenceError); ok {
+		// The object is updated again once the object it references changes.
+		q.logger.Info("waiting for referenced object to be created", zap.String("kind", q.kind), zap.Error(err))
+		return nil
+	}
+	return err
+}
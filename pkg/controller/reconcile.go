@@ -0,0 +1,190 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package controller
+
+import (
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WithReconciliation configures the controller to list the checks in the given namespaces every
+// interval, and delete the checks which no longer exist. This catches deletions the informer
+// didn't report, or which were dropped. The checks the controller synced are deleted from the
+// account they were synced with.
+//
+// Checks can't be renamed, since the name of a Kubernetes object is immutable. Renaming a check
+// deletes it and creates a check with the new name, whose deletion is handled like any other.
+// Changing the name a check has in Pingdom doesn't change the tag which identifies it.
+func WithReconciliation(checks CheckLister, namespaces []string, interval time.Duration) Option {
+	return func(c *Controller) {
+		c.checks = checks
+		c.namespaces = namespaces
+		c.reconcile = interval
+	}
+}
+
+// WithOrphanDeletion configures the controller to also compare the checks orphans manages in
+// Pingdom against the listed checks when reconciling, and delete those whose check no longer
+// exists, which catches checks deleted while heimdallr wasn't running. Orphans are only deleted
+// if the controller watches every namespace and selector is empty. Otherwise the checks of other
+// instances of heimdallr, which are tagged with the same cluster, can't be told apart from
+// orphaned ones.
+func WithOrphanDeletion(orphans OrphanClient, selector string) Option {
+	return func(c *Controller) {
+		c.orphans = orphans
+		c.selector = selector
+	}
+}
+
+func (c *Controller) runReconciler(stop <-chan struct{}) {
+	ticker := time.NewTicker(c.reconcile)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.reconcileDeleted(); err != nil {
+				c.logger.Error("unable to reconcile deleted checks", zap.Error(err))
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// reconcileDeleted queues the deletion of the checks which were synced but no longer exist, and
+// deletes the checks in Pingdom whose check no longer exists.
+func (c *Controller) reconcileDeleted() error {
+	// The synced checks are copied before listing checks, so that checks created since they
+	// were listed aren't mistaken for deleted ones.
+	c.mu.Lock()
+	synced := make(map[string]*v1alpha1.HTTPCheck, len(c.synced))
+	for key, sc := range c.synced {
+		synced[key] = sc.check
+	}
+	c.mu.Unlock()
+
+	orphans := c.orphans != nil && c.selector == "" && c.watchesAll()
+	if len(synced) == 0 && !orphans {
+		return nil
+	}
+
+	var (
+		listed []v1alpha1.HTTPCheck
+		done   bool
+	)
+	list := func() ([]v1alpha1.HTTPCheck, error) {
+		listed = nil
+		for _, ns := range c.namespaces {
+			checks, err := c.checks.ListChecks(ns, "")
+			if err != nil {
+				return nil, err
+			}
+			listed = append(listed, checks...)
+		}
+		done = true
+
+		// Synced checks which no longer exist are deleted through the queue below, so that
+		// they're deleted from the account they were synced with and forgotten.
+		checks := listed
+		for _, chk := range synced {
+			checks = append(checks, *chk)
+		}
+		return checks, nil
+	}
+
+	if orphans {
+		// The synced checks are still reconciled if the orphaned ones can't be, such as when no
+		// credentials are loaded.
+		if err := c.orphans.DeleteOrphanedHTTPChecks(list); err != nil {
+			c.logger.Error("unable to delete orphaned checks", zap.Error(err))
+		}
+	}
+	if !done {
+		// The client didn't list the checks, since it manages none or failed first.
+		if _, err := list(); err != nil {
+			return err
+		}
+	}
+
+	for _, chk := range listed {
+		delete(synced, checkKey(&chk))
+	}
+	for key, chk := range synced {
+		sc, ok := c.syncedCheck(key)
+		if !ok || sc.check.UID != chk.UID {
+			continue
+		}
+		if c.queue.enqueueIdle(key, event{typ: eventDelete, check: sc.check}) {
+			c.logger.Info("deleting check which no longer exists", zap.String("key", key))
+		}
+	}
+	return nil
+}
+
+// watchesAll returns whether the controller watches every namespace.
+func (c *Controller) watchesAll() bool {
+	for _, ns := range c.namespaces {
+		if ns == metav1.NamespaceAll {
+			return true
+		}
+	}
+	return false
+}
+
+// setSynced records the check as synced with the Pingdom account of the given client.
+func (c *Controller) setSynced(chk *v1alpha1.HTTPCheck, client PingdomClient) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.synced[checkKey(chk)] = syncedCheck{
+		check:   chk,
+		account: accountKey(chk),
+		client:  client,
+	}
+}
+
+// forgetSynced records that the check was deleted from Pingdom, unless a newer check with the
+// same name was synced since.
+func (c *Controller) forgetSynced(chk *v1alpha1.HTTPCheck) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := checkKey(chk)
+	if synced, ok := c.synced[key]; ok && synced.check.UID == chk.UID {
+		delete(c.synced, key)
+	}
+}
+
+// syncedCheck returns the check with the given key as it was last synced.
+func (c *Controller) syncedCheck(key string) (syncedCheck, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sc, ok := c.synced[key]
+	return sc, ok
+}
+
+// checkKey returns the key of a check in the queue.
+func checkKey(chk *v1alpha1.HTTPCheck) string {
+	return chk.Namespace + "/" + chk.Name
+}
@@ -0,0 +1,303 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestOnDeleteTombstone(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "check",
+		},
+	}
+
+	cli := NewMockPingdomClient(mCtrl)
+	cli.EXPECT().DeleteHTTPCheck(check).Return(nil)
+
+	ctrl := new(cli, zap.NewNop())
+	ctrl.OnDelete(cache.DeletedFinalStateUnknown{Key: "default/check", Obj: &check})
+	ctrl.processNextItem()
+
+	assert.Equal(t, 0, ctrl.queue.NumRequeues("default/check"))
+}
+
+func TestOnDeleteTombstoneUnknownObject(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "check",
+			UID:       "1",
+		},
+	}
+
+	cli := NewMockPingdomClient(mCtrl)
+	gomock.InOrder(
+		cli.EXPECT().UpdateHTTPCheck(check).Return(nil),
+		cli.EXPECT().DeleteHTTPCheck(gomock.Any()).Return(nil),
+	)
+
+	ctrl := new(cli, zap.NewNop())
+	ctrl.OnAdd(&check)
+	ctrl.processNextItem()
+
+	// The tombstone doesn't hold the check, so the check which was synced is deleted.
+	ctrl.OnDelete(cache.DeletedFinalStateUnknown{Key: "default/check"})
+	ctrl.processNextItem()
+
+	_, ok := ctrl.syncedCheck("default/check")
+	assert.False(t, ok)
+}
+
+func TestOnDeleteTombstoneNotSynced(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	cli := NewMockPingdomClient(mCtrl)
+
+	ctrl := new(cli, zap.NewNop())
+	ctrl.OnDelete(cache.DeletedFinalStateUnknown{Key: "default/check"})
+
+	assert.Equal(t, 0, ctrl.queue.Len())
+}
+
+func TestReconcileDeleted(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	kept := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kept", UID: "1"},
+	}
+	deleted := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deleted", UID: "2"},
+	}
+
+	cli := NewMockPingdomClient(mCtrl)
+	gomock.InOrder(
+		cli.EXPECT().UpdateHTTPCheck(kept).Return(nil),
+		cli.EXPECT().UpdateHTTPCheck(deleted).Return(nil),
+		cli.EXPECT().DeleteHTTPCheck(gomock.Any()).Do(func(chk v1alpha1.HTTPCheck) {
+			assert.Equal(t, "deleted", chk.Name)
+		}).Return(nil),
+	)
+
+	lister := NewMockCheckLister(mCtrl)
+	lister.EXPECT().ListChecks("default", "").Return([]v1alpha1.HTTPCheck{kept}, nil)
+
+	ctrl := new(cli, zap.NewNop(), WithReconciliation(lister, []string{"default"}, time.Minute))
+	ctrl.OnAdd(&kept)
+	ctrl.processNextItem()
+	ctrl.OnAdd(&deleted)
+	ctrl.processNextItem()
+
+	assert.NoError(t, ctrl.reconcileDeleted())
+	assert.Equal(t, 1, ctrl.queue.Len())
+	ctrl.processNextItem()
+
+	_, ok := ctrl.syncedCheck("default/deleted")
+	assert.False(t, ok)
+	_, ok = ctrl.syncedCheck("default/kept")
+	assert.True(t, ok)
+}
+
+func TestReconcileDeletedPending(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "check", UID: "1"},
+	}
+
+	cli := NewMockPingdomClient(mCtrl)
+	cli.EXPECT().UpdateHTTPCheck(check).Return(nil)
+
+	lister := NewMockCheckLister(mCtrl)
+	lister.EXPECT().ListChecks("default", "").Return(nil, nil)
+
+	ctrl := new(cli, zap.NewNop(), WithReconciliation(lister, []string{"default"}, time.Minute))
+	ctrl.OnAdd(&check)
+	ctrl.processNextItem()
+
+	// A change which hasn't been processed yet isn't overwritten by the reconciler.
+	updated := check
+	updated.Spec.URL = "https://example.com"
+	ctrl.OnUpdate(&check, &updated)
+
+	assert.NoError(t, ctrl.reconcileDeleted())
+
+	ctrl.mu.Lock()
+	assert.Equal(t, eventUpdate, ctrl.queue.pending["default/check"].(event).typ)
+	ctrl.mu.Unlock()
+}
+
+func TestReconcileDeletedRenamed(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "check", UID: "1"},
+	}
+	renamed := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "renamed", UID: "2"},
+	}
+
+	cli := NewMockPingdomClient(mCtrl)
+	gomock.InOrder(
+		cli.EXPECT().UpdateHTTPCheck(check).Return(nil),
+		cli.EXPECT().DeleteHTTPCheck(gomock.Any()).Do(func(chk v1alpha1.HTTPCheck) {
+			assert.Equal(t, "check", chk.Name)
+		}).Return(nil),
+	)
+
+	lister := NewMockCheckLister(mCtrl)
+	lister.EXPECT().ListChecks("default", "").Return([]v1alpha1.HTTPCheck{renamed}, nil)
+
+	ctrl := new(cli, zap.NewNop(), WithReconciliation(lister, []string{"default"}, time.Minute))
+	ctrl.OnAdd(&check)
+	ctrl.processNextItem()
+
+	assert.NoError(t, ctrl.reconcileDeleted())
+	ctrl.processNextItem()
+}
+
+func TestReconcileDeletedOrphans(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	synced := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "synced", UID: "1"},
+	}
+	listed := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "listed", UID: "2"},
+	}
+
+	cli := NewMockPingdomClient(mCtrl)
+	cli.EXPECT().UpdateHTTPCheck(synced).Return(nil)
+
+	lister := NewMockCheckLister(mCtrl)
+	lister.EXPECT().ListChecks("", "").Return([]v1alpha1.HTTPCheck{listed}, nil)
+
+	// The checks in Pingdom are compared against the listed checks and the synced ones, whose
+	// deletion is queued instead.
+	orphans := NewMockOrphanClient(mCtrl)
+	orphans.EXPECT().DeleteOrphanedHTTPChecks(gomock.Any()).DoAndReturn(
+		func(list func() ([]v1alpha1.HTTPCheck, error)) error {
+			checks, err := list()
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, []v1alpha1.HTTPCheck{listed, synced}, checks)
+			return nil
+		},
+	)
+
+	ctrl := new(cli, zap.NewNop(),
+		WithReconciliation(lister, []string{""}, time.Minute), WithOrphanDeletion(orphans, ""))
+	ctrl.OnAdd(&synced)
+	ctrl.processNextItem()
+
+	assert.NoError(t, ctrl.reconcileDeleted())
+	assert.Equal(t, 1, ctrl.queue.Len())
+}
+
+func TestReconcileDeletedOrphansNotListed(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "check", UID: "1"},
+	}
+
+	cli := NewMockPingdomClient(mCtrl)
+	cli.EXPECT().UpdateHTTPCheck(check).Return(nil)
+
+	lister := NewMockCheckLister(mCtrl)
+	lister.EXPECT().ListChecks("", "").Return([]v1alpha1.HTTPCheck{check}, nil)
+
+	// The synced checks are still listed if the client manages no checks and doesn't list them.
+	orphans := NewMockOrphanClient(mCtrl)
+	orphans.EXPECT().DeleteOrphanedHTTPChecks(gomock.Any()).Return(nil)
+
+	ctrl := new(cli, zap.NewNop(),
+		WithReconciliation(lister, []string{""}, time.Minute), WithOrphanDeletion(orphans, ""))
+	ctrl.OnAdd(&check)
+	ctrl.processNextItem()
+
+	assert.NoError(t, ctrl.reconcileDeleted())
+	assert.Equal(t, 0, ctrl.queue.Len())
+}
+
+func TestReconcileDeletedOrphansScoped(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	lister := NewMockCheckLister(mCtrl)
+
+	// Checks in Pingdom aren't deleted by a controller watching some namespaces, since they may
+	// belong to a namespace it doesn't watch.
+	orphans := NewMockOrphanClient(mCtrl)
+
+	ctrl := new(NewMockPingdomClient(mCtrl), zap.NewNop(),
+		WithReconciliation(lister, []string{"default"}, time.Minute), WithOrphanDeletion(orphans, ""))
+	assert.NoError(t, ctrl.reconcileDeleted())
+}
+
+func TestReconcileDeletedOrphansSelector(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "check", UID: "1"},
+	}
+
+	cli := NewMockPingdomClient(mCtrl)
+	cli.EXPECT().UpdateHTTPCheck(check).Return(nil)
+
+	// The check of another instance, whose selector doesn't match it, isn't listed by this one.
+	lister := NewMockCheckLister(mCtrl)
+	lister.EXPECT().ListChecks("", "").Return([]v1alpha1.HTTPCheck{check}, nil)
+
+	// Checks in Pingdom aren't deleted by a controller with a selector, since the checks which
+	// aren't listed may belong to another instance watching the same namespaces.
+	orphans := NewMockOrphanClient(mCtrl)
+
+	ctrl := new(cli, zap.NewNop(),
+		WithReconciliation(lister, []string{""}, time.Minute), WithOrphanDeletion(orphans, "team=web"))
+	ctrl.OnAdd(&check)
+	ctrl.processNextItem()
+
+	assert.NoError(t, ctrl.reconcileDeleted())
+	assert.Equal(t, 0, ctrl.queue.Len())
+}
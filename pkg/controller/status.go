@@ -0,0 +1,103 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package controller
+
+import (
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	"github.com/jeromefroe/heimdallr/pkg/pingdom"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Reasons for the Ready condition of a check.
+const (
+	reasonSynced              = "Synced"
+	reasonSyncFailed          = "SyncFailed"
+	reasonUnresolvedReference = "UnresolvedReference"
+	reasonInvalidSpec         = "InvalidSpec"
+	reasonUnauthorized        = "Unauthorized"
+	reasonRateLimited         = "RateLimited"
+	reasonDryRun              = "DryRun"
+)
+
+// readyCondition returns the Ready condition of a check which was synced with the given error.
+func readyCondition(err error) v1alpha1.HTTPCheckCondition {
+	if err == nil {
+		return v1alpha1.HTTPCheckCondition{
+			Type:   v1alpha1.HTTPCheckReady,
+			Status: v1alpha1.ConditionTrue,
+			Reason: reasonSynced,
+		}
+	}
+
+	reason, msg := reasonSyncFailed, err.Error()
+	switch e := err.(type) {
+	case *pingdom.UnresolvedReferenceError:
+		reason = reasonUnresolvedReference
+	case *pingdom.ValidationError:
+		// The message Pingdom gave is more useful to users than the operation which failed.
+		reason, msg = reasonInvalidSpec, e.Message
+		if e.Field != "" {
+			msg = "spec." + e.Field + ": " + e.Message
+		}
+	case *pingdom.AuthError:
+		reason = reasonUnauthorized
+	case *pingdom.RateLimitedError:
+		reason = reasonRateLimited
+	}
+	return v1alpha1.HTTPCheckCondition{
+		Type:    v1alpha1.HTTPCheckReady,
+		Status:  v1alpha1.ConditionFalse,
+		Reason:  reason,
+		Message: msg,
+	}
+}
+
+// dryRunCondition returns the Ready condition of a check which would have been synced, had
+// heimdallr not been running in dry-run mode.
+func dryRunCondition() v1alpha1.HTTPCheckCondition {
+	return v1alpha1.HTTPCheckCondition{
+		Type:    v1alpha1.HTTPCheckReady,
+		Status:  v1alpha1.ConditionUnknown,
+		Reason:  reasonDryRun,
+		Message: "changes to the check were only logged because heimdallr is running in dry-run mode",
+	}
+}
+
+// setCondition adds the condition to the status, replacing any condition of the same type. The
+// last transition time is only changed if the status of the condition changed.
+func setCondition(status *v1alpha1.HTTPCheckStatus, cond v1alpha1.HTTPCheckCondition) {
+	for i, existing := range status.Conditions {
+		if existing.Type != cond.Type {
+			continue
+		}
+		if existing.Status == cond.Status {
+			cond.LastTransitionTime = existing.LastTransitionTime
+		} else {
+			cond.LastTransitionTime = metav1.Now()
+		}
+		status.Conditions[i] = cond
+		return
+	}
+
+	cond.LastTransitionTime = metav1.Now()
+	status.Conditions = append(status.Conditions, cond)
+}
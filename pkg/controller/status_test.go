@@ -0,0 +1,164 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	"github.com/jeromefroe/heimdallr/pkg/pingdom"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetCondition(t *testing.T) {
+	var status v1alpha1.HTTPCheckStatus
+
+	setCondition(&status, readyCondition(errors.New("bad request")))
+	require.Len(t, status.Conditions, 1)
+	failed := status.Conditions[0]
+	assert.Equal(t, v1alpha1.ConditionFalse, failed.Status)
+	assert.Equal(t, reasonSyncFailed, failed.Reason)
+	assert.Equal(t, "bad request", failed.Message)
+	assert.False(t, failed.LastTransitionTime.IsZero())
+
+	// The transition time is kept while the status doesn't change.
+	failed.LastTransitionTime = metav1.Unix(1, 0)
+	status.Conditions[0] = failed
+	setCondition(&status, readyCondition(&pingdom.UnresolvedReferenceError{Kind: "team", Name: "search"}))
+	require.Len(t, status.Conditions, 1)
+	assert.Equal(t, reasonUnresolvedReference, status.Conditions[0].Reason)
+	assert.Equal(t, metav1.Unix(1, 0), status.Conditions[0].LastTransitionTime)
+
+	setCondition(&status, readyCondition(nil))
+	require.Len(t, status.Conditions, 1)
+	assert.Equal(t, v1alpha1.ConditionTrue, status.Conditions[0].Status)
+	assert.Equal(t, reasonSynced, status.Conditions[0].Reason)
+	assert.NotEqual(t, metav1.Unix(1, 0), status.Conditions[0].LastTransitionTime)
+}
+
+func TestReadyConditionTypedErrors(t *testing.T) {
+	cond := readyCondition(&pingdom.ValidationError{
+		Op:      "create check",
+		Field:   "intervalMinutes",
+		Message: "Invalid parameter value => resolution",
+	})
+	assert.Equal(t, reasonInvalidSpec, cond.Reason)
+	assert.Equal(t, "spec.intervalMinutes: Invalid parameter value => resolution", cond.Message)
+
+	cond = readyCondition(&pingdom.ValidationError{Op: "create check", Message: "Bad Request"})
+	assert.Equal(t, reasonInvalidSpec, cond.Reason)
+	assert.Equal(t, "Bad Request", cond.Message)
+
+	cond = readyCondition(&pingdom.AuthError{Op: "create check", Message: "Invalid token"})
+	assert.Equal(t, reasonUnauthorized, cond.Reason)
+	assert.Equal(t, "failed to create check: unauthorized: Invalid token", cond.Message)
+
+	cond = readyCondition(&pingdom.RateLimitedError{Op: "create check", Message: "Too many requests"})
+	assert.Equal(t, reasonRateLimited, cond.Reason)
+}
+
+func TestOnAddUpdatesStatus(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "check",
+		},
+		Spec: v1alpha1.HTTPCheckSpec{
+			Teams: []string{"search"},
+		},
+	}
+
+	cli := NewMockPingdomClient(mCtrl)
+	cli.EXPECT().
+		UpdateHTTPCheck(check).
+		Return(&pingdom.UnresolvedReferenceError{Kind: "team", Name: "search"})
+
+	status := NewMockStatusUpdater(mCtrl)
+	status.EXPECT().UpdateStatus(gomock.Any()).Do(func(chk *v1alpha1.HTTPCheck) {
+		require.Len(t, chk.Status.Conditions, 1)
+		assert.Equal(t, v1alpha1.ConditionFalse, chk.Status.Conditions[0].Status)
+		assert.Equal(t, reasonUnresolvedReference, chk.Status.Conditions[0].Reason)
+		assert.Equal(t, `team "search" does not exist`, chk.Status.Conditions[0].Message)
+	}).Return(nil)
+
+	ctrl := new(cli, zap.NewNop(), WithStatusUpdater(status))
+	ctrl.OnAdd(&check)
+	ctrl.processNextItem()
+
+	assert.Empty(t, check.Status.Conditions, "expected the check in the cache not to be modified")
+}
+
+func TestOnUpdateStatusUnchanged(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "check",
+		},
+		Status: v1alpha1.HTTPCheckStatus{
+			State:      "successfully updated check",
+			Conditions: []v1alpha1.HTTPCheckCondition{readyCondition(nil)},
+		},
+	}
+
+	cli := NewMockPingdomClient(mCtrl)
+	cli.EXPECT().UpdateHTTPCheck(gomock.Any()).Return(nil)
+
+	// A resync of a check whose status is already up to date doesn't update its status.
+	ctrl := new(cli, zap.NewNop(), WithStatusUpdater(NewMockStatusUpdater(mCtrl)))
+	ctrl.OnUpdate(&check, &check)
+	ctrl.processNextItem()
+}
+
+func TestOnUpdateStatusOnly(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	var (
+		oldCheck = v1alpha1.HTTPCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "check",
+				ResourceVersion: "1",
+			},
+		}
+		newCheck = v1alpha1.HTTPCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "check",
+				ResourceVersion: "2",
+			},
+			Status: v1alpha1.HTTPCheckStatus{State: "successfully created check"},
+		}
+	)
+
+	// Changes to the status of a check, made by the controller itself, aren't synced.
+	ctrl := new(NewMockPingdomClient(mCtrl), zap.NewNop())
+	ctrl.OnUpdate(&oldCheck, &newCheck)
+	assert.Equal(t, 0, ctrl.queue.Len())
+}
@@ -20,11 +20,63 @@
 
 package controller
 
-import "github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+import (
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	"github.com/jeromefroe/heimdallr/pkg/pingdom"
+)
 
 //go:generate mockgen -source types.go -destination mocks.go -package controller
 
-type pingdomClient interface {
+// PingdomClient manages the checks of a Pingdom account.
+type PingdomClient interface {
 	UpdateHTTPCheck(check v1alpha1.HTTPCheck) error
 	DeleteHTTPCheck(check v1alpha1.HTTPCheck) error
 }
+
+// OrphanClient deletes the checks of a Pingdom account whose HTTPCheck no longer exists.
+type OrphanClient interface {
+	DeleteOrphanedHTTPChecks(list func() ([]v1alpha1.HTTPCheck, error)) error
+}
+
+// AlertingClient manages the contacts and teams of a Pingdom account.
+type AlertingClient interface {
+	UpdateContact(contact v1alpha1.PingdomContact) error
+	DeleteContact(contact v1alpha1.PingdomContact) error
+	UpdateTeam(team v1alpha1.PingdomTeam) error
+	DeleteTeam(team v1alpha1.PingdomTeam) error
+}
+
+// MaintenanceClient manages the maintenance windows of a Pingdom account.
+type MaintenanceClient interface {
+	UpdateMaintenanceWindow(window v1alpha1.MaintenanceWindow, checks []v1alpha1.HTTPCheck) error
+	DeleteMaintenanceWindow(window v1alpha1.MaintenanceWindow) error
+}
+
+// StateClient reads the state and uptime of checks in Pingdom.
+type StateClient interface {
+	CheckStates(checks []v1alpha1.HTTPCheck) (map[string]pingdom.CheckState, error)
+	Uptimes(check v1alpha1.HTTPCheck, windows []time.Duration) ([]pingdom.Uptime, error)
+}
+
+// CheckLister lists the checks in a namespace which match a label selector.
+type CheckLister interface {
+	ListChecks(namespace, selector string) ([]v1alpha1.HTTPCheck, error)
+}
+
+// RolloutGetter gets whether a Deployment is in the middle of a rollout.
+type RolloutGetter interface {
+	RolloutInProgress(namespace, name string) (bool, error)
+}
+
+// CredentialsGetter gets the Pingdom credentials stored in a Secret along with the resource
+// version of the Secret they were read from.
+type CredentialsGetter interface {
+	GetCredentials(namespace, name string) (pingdom.Credentials, string, error)
+}
+
+// StatusUpdater persists the status of a check.
+type StatusUpdater interface {
+	UpdateStatus(check *v1alpha1.HTTPCheck) error
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package discovery generates heimdallr checks from annotated Kubernetes resources.
+package discovery
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	heimdallrv1 "github.com/jeromefroe/heimdallr/pkg/client/clientset/versioned/typed/heimdallr/v1alpha1"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// CheckAnnotation opts a resource into check generation when set to "true".
+	CheckAnnotation = "heimdallr.froe.io/check"
+
+	// IntervalAnnotation overrides the interval, in minutes, of generated checks.
+	IntervalAnnotation = "heimdallr.froe.io/interval-minutes"
+
+	// TriggerThresholdAnnotation overrides the trigger threshold of generated checks.
+	TriggerThresholdAnnotation = "heimdallr.froe.io/trigger-threshold"
+
+	// RetriggerThresholdAnnotation overrides the retrigger threshold of generated checks.
+	RetriggerThresholdAnnotation = "heimdallr.froe.io/retrigger-threshold"
+
+	// NotifyWhenBackupAnnotation overrides whether generated checks notify when back up.
+	NotifyWhenBackupAnnotation = "heimdallr.froe.io/notify-when-backup"
+
+	// HTTPPortsAnnotation lists the names or numbers of the ports of a Service which serve HTTP,
+	// separated by commas.
+	HTTPPortsAnnotation = "heimdallr.froe.io/http-ports"
+
+	// ownerLabel is the label added to every generated check with the UID of the resource it
+	// was generated from so the checks owned by a resource can be listed.
+	ownerLabel = "heimdallr.froe.io/owner-uid"
+
+	// maxNameLength is the maximum length of the name of a Kubernetes object.
+	maxNameLength = 253
+)
+
+// defaultSpec is the spec generated checks start from before annotations are applied.
+var defaultSpec = v1alpha1.HTTPCheckSpec{
+	IntervalMinutes:  5,
+	TriggerThreshold: 2,
+	NotifyWhenBackup: true,
+}
+
+// enabled returns true if the annotations opt a resource into check generation.
+func enabled(annotations map[string]string) bool {
+	return annotations[CheckAnnotation] == "true"
+}
+
+// specFromAnnotations returns the base spec for the checks generated for a resource.
+func specFromAnnotations(annotations map[string]string) (v1alpha1.HTTPCheckSpec, error) {
+	spec := defaultSpec
+
+	ints := []struct {
+		annotation string
+		field      *int
+	}{
+		{IntervalAnnotation, &spec.IntervalMinutes},
+		{TriggerThresholdAnnotation, &spec.TriggerThreshold},
+		{RetriggerThresholdAnnotation, &spec.RetriggerThreshold},
+	}
+	for _, i := range ints {
+		v, ok := annotations[i.annotation]
+		if !ok {
+			continue
+		}
+
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return v1alpha1.HTTPCheckSpec{}, fmt.Errorf("invalid value %q for annotation %v: %v", v, i.annotation, err)
+		}
+		*i.field = n
+	}
+
+	if v, ok := annotations[NotifyWhenBackupAnnotation]; ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return v1alpha1.HTTPCheckSpec{}, fmt.Errorf(
+				"invalid value %q for annotation %v: %v", v, NotifyWhenBackupAnnotation, err,
+			)
+		}
+		spec.NotifyWhenBackup = b
+	}
+
+	return spec, nil
+}
+
+// checkName returns the name of the check generated for an endpoint of a resource.
+func checkName(owner, endpoint string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(endpoint))
+	suffix := fmt.Sprintf("-%08x", h.Sum32())
+
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '-'
+		}
+	}, owner+"-"+endpoint)
+	name = strings.Trim(name, "-.")
+
+	if len(name) > maxNameLength-len(suffix) {
+		name = strings.TrimRight(name[:maxNameLength-len(suffix)], "-.")
+	}
+	return name + suffix
+}
+
+// reconciler makes the checks owned by a resource match the checks desired for it.
+type reconciler struct {
+	checks heimdallrv1.HTTPChecksGetter
+	logger *zap.Logger
+}
+
+// newCheck returns a check owned by the given resource. The check inherits the labels of its
+// owner so that it's matched by the same label selectors.
+func newCheck(owner metav1.Object, gvk schema.GroupVersionKind, name string, spec v1alpha1.HTTPCheckSpec) v1alpha1.HTTPCheck {
+	labels := make(map[string]string, len(owner.GetLabels())+1)
+	for k, v := range owner.GetLabels() {
+		labels[k] = v
+	}
+	labels[ownerLabel] = string(owner.GetUID())
+
+	isController := true
+	return v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: owner.GetNamespace(),
+			Labels:    labels,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: gvk.GroupVersion().String(),
+					Kind:       gvk.Kind,
+					Name:       owner.GetName(),
+					UID:        owner.GetUID(),
+					Controller: &isController,
+				},
+			},
+		},
+		Spec: spec,
+	}
+}
+
+// reconcile creates, updates and deletes the checks owned by the given resource so that they
+// match the desired checks. Checks which are no longer desired are deleted, the remaining
+// checks are garbage collected by Kubernetes when the owner itself is deleted.
+func (r *reconciler) reconcile(owner metav1.Object, desired []v1alpha1.HTTPCheck) error {
+	client := r.checks.HTTPChecks(owner.GetNamespace())
+
+	list, err := client.List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", ownerLabel, owner.GetUID()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list existing checks: %v", err)
+	}
+
+	existing := make(map[string]v1alpha1.HTTPCheck, len(list.Items))
+	for _, chk := range list.Items {
+		existing[chk.Name] = chk
+	}
+
+	for _, chk := range desired {
+		cur, ok := existing[chk.Name]
+		if !ok {
+			if _, err := client.Create(&chk); err != nil && !errors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to create check %v: %v", chk.Name, err)
+			}
+			r.logger.Info("created check", zap.String("namespace", chk.Namespace), zap.String("name", chk.Name))
+			continue
+		}
+		delete(existing, chk.Name)
+
+		if reflect.DeepEqual(cur.Spec, chk.Spec) && reflect.DeepEqual(cur.Labels, chk.Labels) {
+			continue
+		}
+
+		cur.Spec = chk.Spec
+		cur.Labels = chk.Labels
+		if _, err := client.Update(&cur); err != nil {
+			return fmt.Errorf("failed to update check %v: %v", chk.Name, err)
+		}
+		r.logger.Info("updated check", zap.String("namespace", chk.Namespace), zap.String("name", chk.Name))
+	}
+
+	for name := range existing {
+		if err := client.Delete(name, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete check %v: %v", name, err)
+		}
+		r.logger.Info("deleted check", zap.String("namespace", owner.GetNamespace()), zap.String("name", name))
+	}
+
+	return nil
+}
+
+func (r *reconciler) logUnexpected(fn string, obj interface{}) {
+	r.logger.Error(
+		"unexpected object received",
+		zap.String("function", fn),
+		zap.Any("object", obj),
+		zap.String("type", fmt.Sprintf("%T", obj)),
+	)
+}
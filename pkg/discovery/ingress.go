@@ -0,0 +1,165 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package discovery
+
+import (
+	"strings"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	heimdallrv1 "github.com/jeromefroe/heimdallr/pkg/client/clientset/versioned/typed/heimdallr/v1alpha1"
+
+	"go.uber.org/zap"
+	extv1beta1 "k8s.io/api/extensions/v1beta1"
+)
+
+// ingressKind is the kind used in the owner references of checks generated from Ingresses.
+var ingressKind = extv1beta1.SchemeGroupVersion.WithKind("Ingress")
+
+// IngressHandler watches for annotated Ingresses and generates a check for every host and
+// path they expose.
+type IngressHandler struct {
+	reconciler
+}
+
+// NewIngressHandler creates a new Ingress handler.
+func NewIngressHandler(checks heimdallrv1.HTTPChecksGetter, logger *zap.Logger) *IngressHandler {
+	return &IngressHandler{
+		reconciler: reconciler{
+			checks: checks,
+			logger: logger,
+		},
+	}
+}
+
+// OnAdd handles new Ingresses.
+func (h *IngressHandler) OnAdd(obj interface{}) {
+	ing, ok := obj.(*extv1beta1.Ingress)
+	if !ok {
+		h.logUnexpected("OnAdd", obj)
+		return
+	}
+
+	h.sync(ing)
+}
+
+// OnUpdate handles updated Ingresses.
+func (h *IngressHandler) OnUpdate(oldObj, newObj interface{}) {
+	ing, ok := newObj.(*extv1beta1.Ingress)
+	if !ok {
+		h.logUnexpected("OnUpdate", newObj)
+		return
+	}
+
+	h.sync(ing)
+}
+
+// OnDelete handles deleted Ingresses. The checks generated for an Ingress are owned by it so
+// they are removed by the Kubernetes garbage collector.
+func (h *IngressHandler) OnDelete(obj interface{}) {}
+
+func (h *IngressHandler) sync(ing *extv1beta1.Ingress) {
+	checks, err := ingressChecks(ing)
+	if err != nil {
+		h.logger.Error(
+			"unable to generate checks for ingress",
+			zap.String("namespace", ing.Namespace),
+			zap.String("name", ing.Name),
+			zap.Error(err),
+		)
+		return
+	}
+
+	if err := h.reconcile(ing, checks); err != nil {
+		h.logger.Error(
+			"unexpected error encountered reconciling checks for ingress",
+			zap.String("namespace", ing.Namespace),
+			zap.String("name", ing.Name),
+			zap.Error(err),
+		)
+	}
+}
+
+// ingressChecks returns the checks that should exist for an Ingress.
+func ingressChecks(ing *extv1beta1.Ingress) ([]v1alpha1.HTTPCheck, error) {
+	if !enabled(ing.Annotations) {
+		return nil, nil
+	}
+
+	base, err := specFromAnnotations(ing.Annotations)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsHosts := make(map[string]bool)
+	for _, tls := range ing.Spec.TLS {
+		for _, host := range tls.Hosts {
+			tlsHosts[host] = true
+		}
+	}
+
+	var (
+		checks []v1alpha1.HTTPCheck
+		seen   = make(map[string]bool)
+	)
+	for _, rule := range ing.Spec.Rules {
+		// Rules without a host or with a wildcard host don't identify an endpoint we can check.
+		if rule.Host == "" || strings.HasPrefix(rule.Host, "*") {
+			continue
+		}
+
+		paths := []string{"/"}
+		if rule.HTTP != nil && len(rule.HTTP.Paths) > 0 {
+			paths = paths[:0]
+			for _, p := range rule.HTTP.Paths {
+				paths = append(paths, checkPath(p.Path))
+			}
+		}
+
+		for _, path := range paths {
+			endpoint := rule.Host + path
+			if seen[endpoint] {
+				continue
+			}
+			seen[endpoint] = true
+
+			spec := base
+			spec.Hostname = rule.Host
+			spec.URL = path
+			spec.EnableTLS = tlsHosts[rule.Host]
+
+			checks = append(checks, newCheck(ing, ingressKind, checkName(ing.Name, endpoint), spec))
+		}
+	}
+
+	return checks, nil
+}
+
+// checkPath converts the path of an Ingress rule, which may be a prefix or a pattern, into a
+// concrete path that can be requested.
+func checkPath(path string) string {
+	if i := strings.IndexAny(path, "*("); i >= 0 {
+		path = path[:i]
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
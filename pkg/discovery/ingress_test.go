@@ -0,0 +1,206 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package discovery
+
+import (
+	"testing"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	"github.com/jeromefroe/heimdallr/pkg/client/clientset/versioned/fake"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	extv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newIngress(annotations map[string]string, rules ...extv1beta1.IngressRule) *extv1beta1.Ingress {
+	return &extv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "other",
+			UID:         "1234",
+			Annotations: annotations,
+		},
+		Spec: extv1beta1.IngressSpec{
+			TLS: []extv1beta1.IngressTLS{
+				{
+					Hosts: []string{"foo.io"},
+				},
+			},
+			Rules: rules,
+		},
+	}
+}
+
+func newRule(host string, paths ...string) extv1beta1.IngressRule {
+	rule := extv1beta1.IngressRule{Host: host}
+	if len(paths) == 0 {
+		return rule
+	}
+
+	rule.HTTP = &extv1beta1.HTTPIngressRuleValue{}
+	for _, p := range paths {
+		rule.HTTP.Paths = append(rule.HTTP.Paths, extv1beta1.HTTPIngressPath{Path: p})
+	}
+	return rule
+}
+
+func listChecks(t *testing.T, cli *fake.Clientset) map[string]v1alpha1.HTTPCheck {
+	list, err := cli.HeimdallrV1alpha1().HTTPChecks("other").List(metav1.ListOptions{})
+	require.NoError(t, err)
+
+	checks := make(map[string]v1alpha1.HTTPCheck)
+	for _, chk := range list.Items {
+		checks[chk.Spec.Hostname+chk.Spec.URL] = chk
+	}
+	return checks
+}
+
+func TestIngressChecks(t *testing.T) {
+	ing := newIngress(
+		map[string]string{
+			CheckAnnotation:            "true",
+			IntervalAnnotation:         "1",
+			TriggerThresholdAnnotation: "3",
+		},
+		newRule("foo.io"),
+		newRule("bar.com", "/api", "/static/*"),
+		newRule("*.wildcard.com"),
+	)
+
+	checks, err := ingressChecks(ing)
+	require.NoError(t, err)
+	require.Len(t, checks, 3)
+
+	expected := []v1alpha1.HTTPCheckSpec{
+		{
+			Hostname:         "foo.io",
+			URL:              "/",
+			IntervalMinutes:  1,
+			TriggerThreshold: 3,
+			NotifyWhenBackup: true,
+			EnableTLS:        true,
+		},
+		{
+			Hostname:         "bar.com",
+			URL:              "/api",
+			IntervalMinutes:  1,
+			TriggerThreshold: 3,
+			NotifyWhenBackup: true,
+		},
+		{
+			Hostname:         "bar.com",
+			URL:              "/static/",
+			IntervalMinutes:  1,
+			TriggerThreshold: 3,
+			NotifyWhenBackup: true,
+		},
+	}
+	for i, chk := range checks {
+		assert.Equal(t, expected[i], chk.Spec)
+		assert.Equal(t, "other", chk.Namespace)
+		require.Len(t, chk.OwnerReferences, 1)
+		assert.Equal(t, "Ingress", chk.OwnerReferences[0].Kind)
+		assert.Equal(t, "web", chk.OwnerReferences[0].Name)
+	}
+}
+
+func TestIngressChecksNotEnabled(t *testing.T) {
+	ing := newIngress(nil, newRule("foo.io"))
+
+	checks, err := ingressChecks(ing)
+	require.NoError(t, err)
+	assert.Empty(t, checks)
+}
+
+func TestIngressChecksInvalidAnnotation(t *testing.T) {
+	ing := newIngress(
+		map[string]string{
+			CheckAnnotation:    "true",
+			IntervalAnnotation: "often",
+		},
+		newRule("foo.io"),
+	)
+
+	_, err := ingressChecks(ing)
+	assert.Error(t, err)
+}
+
+func TestIngressHandler(t *testing.T) {
+	var (
+		cli     = fake.NewSimpleClientset()
+		handler = NewIngressHandler(cli.HeimdallrV1alpha1(), zap.NewNop())
+		ing     = newIngress(
+			map[string]string{CheckAnnotation: "true"},
+			newRule("foo.io"),
+			newRule("bar.com"),
+		)
+	)
+
+	handler.OnAdd(ing)
+	checks := listChecks(t, cli)
+	assert.Len(t, checks, 2)
+	assert.Contains(t, checks, "foo.io/")
+	assert.Contains(t, checks, "bar.com/")
+
+	updated := ing.DeepCopy()
+	updated.Annotations[IntervalAnnotation] = "15"
+	updated.Spec.Rules = updated.Spec.Rules[:1]
+
+	handler.OnUpdate(ing, updated)
+	checks = listChecks(t, cli)
+	require.Len(t, checks, 1)
+	assert.Equal(t, 15, checks["foo.io/"].Spec.IntervalMinutes)
+
+	disabled := updated.DeepCopy()
+	delete(disabled.Annotations, CheckAnnotation)
+
+	handler.OnUpdate(updated, disabled)
+	assert.Empty(t, listChecks(t, cli))
+}
+
+func TestIngressHandlerLabels(t *testing.T) {
+	var (
+		cli     = fake.NewSimpleClientset()
+		handler = NewIngressHandler(cli.HeimdallrV1alpha1(), zap.NewNop())
+		ing     = newIngress(map[string]string{CheckAnnotation: "true"}, newRule("foo.io"))
+	)
+	ing.Labels = map[string]string{"env": "staging"}
+
+	handler.OnAdd(ing)
+	checks := listChecks(t, cli)
+	assert.Equal(t, map[string]string{"env": "staging", ownerLabel: "1234"}, checks["foo.io/"].Labels)
+
+	updated := ing.DeepCopy()
+	updated.Labels["env"] = "production"
+
+	handler.OnUpdate(ing, updated)
+	checks = listChecks(t, cli)
+	assert.Equal(t, "production", checks["foo.io/"].Labels["env"])
+}
+
+func TestCheckName(t *testing.T) {
+	name := checkName("Web", "foo.io/api/v1")
+	assert.Regexp(t, "^web-foo.io-api-v1-[0-9a-f]{8}$", name)
+	assert.NotEqual(t, name, checkName("Web", "foo.io/api-v1"))
+}
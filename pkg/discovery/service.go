@@ -0,0 +1,191 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package discovery
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	heimdallrv1 "github.com/jeromefroe/heimdallr/pkg/client/clientset/versioned/typed/heimdallr/v1alpha1"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// serviceKind is the kind used in the owner references of checks generated from Services.
+var serviceKind = corev1.SchemeGroupVersion.WithKind("Service")
+
+// ServiceHandler watches for annotated LoadBalancer Services and generates a check for every TCP
+// port they expose once their load balancer has been provisioned. Ports serving HTTP get HTTP
+// checks, and other ports, such as a database's, get TCP checks which only open a connection.
+type ServiceHandler struct {
+	reconciler
+}
+
+// NewServiceHandler creates a new Service handler.
+func NewServiceHandler(checks heimdallrv1.HTTPChecksGetter, logger *zap.Logger) *ServiceHandler {
+	return &ServiceHandler{
+		reconciler: reconciler{
+			checks: checks,
+			logger: logger,
+		},
+	}
+}
+
+// OnAdd handles new Services.
+func (h *ServiceHandler) OnAdd(obj interface{}) {
+	svc, ok := obj.(*corev1.Service)
+	if !ok {
+		h.logUnexpected("OnAdd", obj)
+		return
+	}
+
+	h.sync(svc)
+}
+
+// OnUpdate handles updated Services.
+func (h *ServiceHandler) OnUpdate(oldObj, newObj interface{}) {
+	svc, ok := newObj.(*corev1.Service)
+	if !ok {
+		h.logUnexpected("OnUpdate", newObj)
+		return
+	}
+
+	h.sync(svc)
+}
+
+// OnDelete handles deleted Services. The checks generated for a Service are owned by it so
+// they are removed by the Kubernetes garbage collector.
+func (h *ServiceHandler) OnDelete(obj interface{}) {}
+
+func (h *ServiceHandler) sync(svc *corev1.Service) {
+	checks, err := serviceChecks(svc)
+	if err != nil {
+		h.logger.Error(
+			"unable to generate checks for service",
+			zap.String("namespace", svc.Namespace),
+			zap.String("name", svc.Name),
+			zap.Error(err),
+		)
+		return
+	}
+
+	if err := h.reconcile(svc, checks); err != nil {
+		h.logger.Error(
+			"unexpected error encountered reconciling checks for service",
+			zap.String("namespace", svc.Namespace),
+			zap.String("name", svc.Name),
+			zap.Error(err),
+		)
+	}
+}
+
+// serviceChecks returns the checks that should exist for a Service.
+func serviceChecks(svc *corev1.Service) ([]v1alpha1.HTTPCheck, error) {
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer || !enabled(svc.Annotations) {
+		return nil, nil
+	}
+
+	address := loadBalancerAddress(svc)
+	if address == "" {
+		// The load balancer hasn't been provisioned yet, we'll be notified when it is.
+		return nil, nil
+	}
+
+	base, err := specFromAnnotations(svc.Annotations)
+	if err != nil {
+		return nil, err
+	}
+
+	isHTTP := defaultHTTPPort
+	if v, ok := svc.Annotations[HTTPPortsAnnotation]; ok {
+		isHTTP = annotatedHTTPPort(v)
+	}
+
+	var checks []v1alpha1.HTTPCheck
+	for _, port := range svc.Spec.Ports {
+		if port.Protocol != "" && port.Protocol != corev1.ProtocolTCP {
+			continue
+		}
+
+		spec := base
+		spec.Hostname = address
+		spec.Port = int(port.Port)
+		if isHTTP(port) {
+			spec.EnableTLS = port.Port == 443 || port.Name == "https"
+		} else {
+			spec.Type = v1alpha1.CheckTypeTCP
+		}
+
+		// The name of a check is based on the port rather than the address so that the
+		// check is updated, rather than replaced, if the address changes.
+		endpoint := port.Name
+		if endpoint == "" {
+			endpoint = strconv.Itoa(int(port.Port))
+		}
+
+		checks = append(checks, newCheck(svc, serviceKind, checkName(svc.Name, endpoint), spec))
+	}
+
+	return checks, nil
+}
+
+// defaultHTTPPort returns true if a port is assumed to serve HTTP when a Service doesn't list its
+// HTTP ports, either because of its name or its number.
+func defaultHTTPPort(port corev1.ServicePort) bool {
+	switch {
+	case port.Name == "http", port.Name == "https":
+		return true
+	case strings.HasPrefix(port.Name, "http-"), strings.HasPrefix(port.Name, "https-"):
+		return true
+	default:
+		return port.Port == 80 || port.Port == 443
+	}
+}
+
+// annotatedHTTPPort returns a function which returns true if a port is one of the comma separated
+// names or numbers of an annotation.
+func annotatedHTTPPort(annotation string) func(corev1.ServicePort) bool {
+	ports := make(map[string]bool)
+	for _, p := range strings.Split(annotation, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			ports[p] = true
+		}
+	}
+	return func(port corev1.ServicePort) bool {
+		return (port.Name != "" && ports[port.Name]) || ports[strconv.Itoa(int(port.Port))]
+	}
+}
+
+// loadBalancerAddress returns the address of the load balancer provisioned for a Service, or
+// the empty string if it hasn't been provisioned yet.
+func loadBalancerAddress(svc *corev1.Service) string {
+	for _, ing := range svc.Status.LoadBalancer.Ingress {
+		if ing.Hostname != "" {
+			return ing.Hostname
+		}
+		if ing.IP != "" {
+			return ing.IP
+		}
+	}
+	return ""
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package discovery
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+	"github.com/jeromefroe/heimdallr/pkg/client/clientset/versioned/fake"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newService(annotations map[string]string, address string) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "api",
+			Namespace:   "other",
+			UID:         "5678",
+			Annotations: annotations,
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeLoadBalancer,
+			Ports: []corev1.ServicePort{
+				{
+					Name:     "https",
+					Protocol: corev1.ProtocolTCP,
+					Port:     443,
+				},
+				{
+					Name:     "dns",
+					Protocol: corev1.ProtocolUDP,
+					Port:     53,
+				},
+				{
+					Protocol: corev1.ProtocolTCP,
+					Port:     8080,
+				},
+				{
+					Name:     "postgres",
+					Protocol: corev1.ProtocolTCP,
+					Port:     5432,
+				},
+			},
+		},
+	}
+
+	if address != "" {
+		svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{
+			{
+				IP: address,
+			},
+		}
+	}
+	return svc
+}
+
+func TestServiceChecksDefaultPorts(t *testing.T) {
+	svc := newService(map[string]string{CheckAnnotation: "true"}, "10.0.0.1")
+
+	// Only the port named https is known to serve HTTP, the other TCP ports get TCP checks.
+	checks, err := serviceChecks(svc)
+	require.NoError(t, err)
+	require.Len(t, checks, 3)
+
+	types := make(map[int]v1alpha1.CheckType)
+	for _, chk := range checks {
+		types[chk.Spec.Port] = chk.Spec.Type
+	}
+	assert.Equal(t, map[int]v1alpha1.CheckType{
+		443:  "",
+		8080: v1alpha1.CheckTypeTCP,
+		5432: v1alpha1.CheckTypeTCP,
+	}, types)
+}
+
+func TestServiceChecks(t *testing.T) {
+	svc := newService(map[string]string{CheckAnnotation: "true", HTTPPortsAnnotation: "https, 8080"}, "10.0.0.1")
+
+	checks, err := serviceChecks(svc)
+	require.NoError(t, err)
+	require.Len(t, checks, 3)
+
+	expected := []v1alpha1.HTTPCheckSpec{
+		{
+			Hostname:         "10.0.0.1",
+			Port:             443,
+			IntervalMinutes:  5,
+			TriggerThreshold: 2,
+			NotifyWhenBackup: true,
+			EnableTLS:        true,
+		},
+		{
+			Hostname:         "10.0.0.1",
+			Port:             8080,
+			IntervalMinutes:  5,
+			TriggerThreshold: 2,
+			NotifyWhenBackup: true,
+		},
+		{
+			Type:             v1alpha1.CheckTypeTCP,
+			Hostname:         "10.0.0.1",
+			Port:             5432,
+			IntervalMinutes:  5,
+			TriggerThreshold: 2,
+			NotifyWhenBackup: true,
+		},
+	}
+	for i, chk := range checks {
+		assert.Equal(t, expected[i], chk.Spec)
+		require.Len(t, chk.OwnerReferences, 1)
+		assert.Equal(t, "Service", chk.OwnerReferences[0].Kind)
+	}
+}
+
+func TestServiceChecksNotProvisioned(t *testing.T) {
+	svc := newService(map[string]string{CheckAnnotation: "true"}, "")
+
+	checks, err := serviceChecks(svc)
+	require.NoError(t, err)
+	assert.Empty(t, checks)
+}
+
+func TestServiceChecksNotLoadBalancer(t *testing.T) {
+	svc := newService(map[string]string{CheckAnnotation: "true"}, "10.0.0.1")
+	svc.Spec.Type = corev1.ServiceTypeClusterIP
+
+	checks, err := serviceChecks(svc)
+	require.NoError(t, err)
+	assert.Empty(t, checks)
+}
+
+func listAddresses(t *testing.T, cli *fake.Clientset) []string {
+	list, err := cli.HeimdallrV1alpha1().HTTPChecks("other").List(metav1.ListOptions{})
+	require.NoError(t, err)
+
+	var addresses []string
+	for _, chk := range list.Items {
+		addresses = append(addresses, fmt.Sprintf("%s:%d", chk.Spec.Hostname, chk.Spec.Port))
+	}
+	return addresses
+}
+
+func TestServiceHandlerAddressChange(t *testing.T) {
+	var (
+		cli     = fake.NewSimpleClientset()
+		handler = NewServiceHandler(cli.HeimdallrV1alpha1(), zap.NewNop())
+		annots  = map[string]string{CheckAnnotation: "true", HTTPPortsAnnotation: "https,8080"}
+		pending = newService(annots, "")
+	)
+
+	handler.OnAdd(pending)
+	assert.Empty(t, listAddresses(t, cli))
+
+	provisioned := newService(annots, "10.0.0.1")
+	handler.OnUpdate(pending, provisioned)
+	assert.ElementsMatch(t, []string{"10.0.0.1:443", "10.0.0.1:8080", "10.0.0.1:5432"}, listAddresses(t, cli))
+
+	moved := newService(annots, "10.0.0.2")
+	handler.OnUpdate(provisioned, moved)
+	assert.ElementsMatch(t, []string{"10.0.0.2:443", "10.0.0.2:8080", "10.0.0.2:5432"}, listAddresses(t, cli))
+}
@@ -0,0 +1,325 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/russellcardullo/go-pingdom/pingdom"
+	"go.uber.org/zap"
+)
+
+// UpdateContact updates a Pingdom contact, creating it if it does not exist. Contacts are
+// identified by their namespace, name and the client's owner marker, and alerting targets of an
+// existing contact which aren't in the spec are removed. A user of the same name without the
+// namespace and marker is only adopted, and renamed, if the contact's spec allows it.
+func (c *Client) UpdateContact(contact v1alpha1.PingdomContact) error {
+	name := qualifiedName(contact.Namespace, contactName(contact))
+	existing, found, err := c.findUser(name, contactName(contact), contact.Spec.Adopt)
+	if err != nil {
+		return err
+	}
+
+	if c.dryRun {
+		c.logger.Info("dry run: would update contact", zap.String("name", name), zap.Bool("exists", found))
+		return nil
+	}
+
+	user := pingdom.User{Username: c.ownedName(name), Paused: "NO"}
+	if contact.Spec.Paused {
+		user.Paused = "YES"
+	}
+
+	id := existing.Id
+	if found {
+		if _, err := c.client.Users().Update(id, user); err != nil {
+			return classify("update contact", err)
+		}
+	} else {
+		res, err := c.client.Users().Create(user)
+		if err != nil {
+			return classify("create contact", err)
+		}
+		id = res.Id
+	}
+
+	if err := c.syncTargets(id, existing, contact.Spec); err != nil {
+		return err
+	}
+
+	c.directory.add("contact", name, id)
+	c.logger.Info("successfully updated contact", zap.String("name", name))
+	return nil
+}
+
+// findUser returns the Pingdom user managed by the client for the contact with the given name.
+// If there isn't one, a user with the unowned name is returned if it may be adopted, and an error
+// if it may not.
+func (c *Client) findUser(name, unownedName string, adopt bool) (pingdom.UsersResponse, bool, error) {
+	users, err := c.client.Users().List()
+	if err != nil {
+		return pingdom.UsersResponse{}, false, classify("get list of users for account", err)
+	}
+
+	var (
+		unowned pingdom.UsersResponse
+		found   bool
+	)
+	for _, user := range users {
+		switch user.Username {
+		case c.ownedName(name):
+			return user, true, nil
+		case unownedName:
+			unowned, found = user, true
+		}
+	}
+
+	if found && !adopt {
+		return pingdom.UsersResponse{}, false, &ValidationError{
+			Op:      "update contact",
+			Field:   "adopt",
+			Message: fmt.Sprintf("user %q exists but isn't managed by heimdallr, set adopt to manage it", unownedName),
+		}
+	}
+	return unowned, found, nil
+}
+
+// syncTargets removes the alerting targets of a user which aren't in the spec and creates those
+// which don't exist yet.
+func (c *Client) syncTargets(id int, user pingdom.UsersResponse, spec v1alpha1.PingdomContactSpec) error {
+	desired := make(map[string]pingdom.Contact)
+	for _, email := range spec.Email {
+		target := pingdom.Contact{Email: email.Address, Severity: severity(email.Severity)}
+		desired[targetKey(target)] = target
+	}
+	for _, sms := range spec.SMS {
+		target := pingdom.Contact{
+			CountryCode: sms.CountryCode,
+			Number:      sms.Number,
+			Provider:    sms.Provider,
+			Severity:    severity(sms.Severity),
+		}
+		desired[targetKey(target)] = target
+	}
+
+	existing := make(map[string]int)
+	for _, email := range user.Email {
+		existing[targetKey(pingdom.Contact{Email: email.Address, Severity: email.Severity})] = email.Id
+	}
+	for _, sms := range user.Sms {
+		existing[targetKey(pingdom.Contact{
+			CountryCode: sms.CountryCode,
+			Number:      sms.Number,
+			Provider:    sms.Provider,
+			Severity:    sms.Severity,
+		})] = sms.Id
+	}
+
+	for key, targetID := range existing {
+		if _, ok := desired[key]; ok {
+			continue
+		}
+		if _, err := c.client.Users().DeleteContact(id, targetID); err != nil {
+			return classify("delete alerting target of contact", err)
+		}
+	}
+	for key, target := range desired {
+		if _, ok := existing[key]; ok {
+			continue
+		}
+		if _, err := c.client.Users().CreateContact(id, target); err != nil {
+			return classify("create alerting target of contact", err)
+		}
+	}
+	return nil
+}
+
+// DeleteContact deletes a Pingdom contact. Only the user managed by the client is deleted, users
+// of the same name which heimdallr didn't create or adopt are left alone.
+func (c *Client) DeleteContact(contact v1alpha1.PingdomContact) error {
+	name := qualifiedName(contact.Namespace, contactName(contact))
+	users, err := c.client.Users().List()
+	if err != nil {
+		return classify("get list of users for account", err)
+	}
+
+	for _, user := range users {
+		if user.Username != c.ownedName(name) {
+			continue
+		}
+
+		if c.dryRun {
+			c.logger.Info("dry run: would delete contact", zap.String("name", name))
+			return nil
+		}
+		if _, err := c.client.Users().Delete(user.Id); err != nil {
+			return classify("delete contact", err)
+		}
+		c.directory.forget("contact", name)
+		c.logger.Info("successfully deleted contact", zap.String("name", name))
+		return nil
+	}
+	return nil
+}
+
+// UpdateTeam updates a Pingdom team, creating it if it does not exist. Teams are identified by
+// their namespace, name and the client's owner marker, and a team of the same name without the
+// namespace and marker is only adopted if the team's spec allows it. Members are resolved like
+// the contacts of a check in the team's namespace.
+func (c *Client) UpdateTeam(team v1alpha1.PingdomTeam) error {
+	name := qualifiedName(team.Namespace, teamName(team))
+	members, err := c.directory.resolve(c.client, c.marker(), team.Namespace, "contact", team.Spec.Members)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, len(members))
+	for i, id := range members {
+		ids[i] = strconv.Itoa(id)
+	}
+	data := pingdom.TeamData{Name: c.ownedName(name), UserIds: strings.Join(ids, ",")}
+
+	existing, found, err := c.findTeam(name, teamName(team), team.Spec.Adopt)
+	if err != nil {
+		return err
+	}
+
+	if c.dryRun {
+		c.logger.Info("dry run: would update team", zap.String("name", name), zap.Bool("exists", found))
+		return nil
+	}
+
+	id := existing.ID
+	if found {
+		if _, err := c.client.Teams().Update(id, data); err != nil {
+			return classify("update team", err)
+		}
+	} else {
+		res, err := c.client.Teams().Create(data)
+		if err != nil {
+			return classify("create team", err)
+		}
+		id = res.ID
+	}
+
+	c.directory.add("team", name, id)
+	c.logger.Info("successfully updated team", zap.String("name", name))
+	return nil
+}
+
+// DeleteTeam deletes a Pingdom team. Only the team managed by the client is deleted, teams of the
+// same name which heimdallr didn't create or adopt are left alone.
+func (c *Client) DeleteTeam(team v1alpha1.PingdomTeam) error {
+	name := qualifiedName(team.Namespace, teamName(team))
+	teams, err := c.client.Teams().List()
+	if err != nil {
+		return classify("get list of teams for account", err)
+	}
+
+	for _, existing := range teams {
+		if existing.Name != c.ownedName(name) {
+			continue
+		}
+
+		if c.dryRun {
+			c.logger.Info("dry run: would delete team", zap.String("name", name))
+			return nil
+		}
+		if _, err := c.client.Teams().Delete(existing.ID); err != nil {
+			return classify("delete team", err)
+		}
+		c.directory.forget("team", name)
+		c.logger.Info("successfully deleted team", zap.String("name", name))
+		return nil
+	}
+	return nil
+}
+
+// findTeam returns the Pingdom team managed by the client for the team with the given name. If
+// there isn't one, a team with the unowned name is returned if it may be adopted, and an error if
+// it may not.
+func (c *Client) findTeam(name, unownedName string, adopt bool) (pingdom.TeamResponse, bool, error) {
+	teams, err := c.client.Teams().List()
+	if err != nil {
+		return pingdom.TeamResponse{}, false, classify("get list of teams for account", err)
+	}
+
+	var (
+		unowned pingdom.TeamResponse
+		found   bool
+	)
+	for _, team := range teams {
+		switch team.Name {
+		case c.ownedName(name):
+			return team, true, nil
+		case unownedName:
+			unowned, found = team, true
+		}
+	}
+
+	if found && !adopt {
+		return pingdom.TeamResponse{}, false, &ValidationError{
+			Op:      "update team",
+			Field:   "adopt",
+			Message: fmt.Sprintf("team %q exists but isn't managed by heimdallr, set adopt to manage it", unownedName),
+		}
+	}
+	return unowned, found, nil
+}
+
+// contactName returns the name of the Pingdom contact for the given contact, without its
+// namespace.
+func contactName(contact v1alpha1.PingdomContact) string {
+	if contact.Spec.Name != "" {
+		return contact.Spec.Name
+	}
+	return contact.Name
+}
+
+// teamName returns the name of the Pingdom team for the given team, without its namespace.
+func teamName(team v1alpha1.PingdomTeam) string {
+	if team.Spec.Name != "" {
+		return team.Spec.Name
+	}
+	return team.Name
+}
+
+// severity returns the given severity of an alerting target, defaulting to high.
+func severity(s string) string {
+	if s == "" {
+		return "HIGH"
+	}
+	return strings.ToUpper(s)
+}
+
+func targetKey(target pingdom.Contact) string {
+	return strings.Join([]string{
+		target.Email,
+		target.CountryCode,
+		target.Number,
+		target.Provider,
+		strings.ToUpper(target.Severity),
+	}, "|")
+}
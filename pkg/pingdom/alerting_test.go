@@ -0,0 +1,242 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"testing"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/golang/mock/gomock"
+	"github.com/russellcardullo/go-pingdom/pingdom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateContact(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		users = NewMockuserService(ctrl)
+		cli   = NewMockpingdomClient(ctrl)
+
+		contact = v1alpha1.PingdomContact{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "alice"},
+			Spec: v1alpha1.PingdomContactSpec{
+				Email: []v1alpha1.EmailTarget{{Address: "alice@example.com"}},
+				SMS:   []v1alpha1.SMSTarget{{CountryCode: "1", Number: "5555555555", Severity: "low"}},
+			},
+		}
+	)
+
+	// The email target already exists, the stale one is removed and the SMS target is created.
+	users.EXPECT().List().Return([]pingdom.UsersResponse{
+		{
+			Id:       7,
+			Username: "web/alice [heimdallr]",
+			Email: []pingdom.UserEmailResponse{
+				{Id: 1, Address: "alice@example.com", Severity: "HIGH"},
+				{Id: 2, Address: "old@example.com", Severity: "HIGH"},
+			},
+		},
+	}, nil)
+	users.EXPECT().Update(7, pingdom.User{Username: "web/alice [heimdallr]", Paused: "NO"}).Return(nil, nil)
+	users.EXPECT().DeleteContact(7, 2).Return(nil, nil)
+	users.EXPECT().
+		CreateContact(7, pingdom.Contact{CountryCode: "1", Number: "5555555555", Severity: "LOW"}).
+		Return(&pingdom.CreateUserContactResponse{Id: 3}, nil)
+	cli.EXPECT().Users().AnyTimes().Return(users)
+
+	client := &Client{client: cli, logger: zap.NewNop()}
+	require.NoError(t, client.UpdateContact(contact))
+
+	// The contact can be referenced by checks without reloading the directory.
+	ids, err := client.directory.resolve(cli, client.marker(), "web", "contact", []string{"alice"})
+	require.NoError(t, err)
+	assert.Equal(t, []int{7}, ids)
+}
+
+func TestCreateContact(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		users = NewMockuserService(ctrl)
+		cli   = NewMockpingdomClient(ctrl)
+
+		contact = v1alpha1.PingdomContact{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "alice"},
+			Spec: v1alpha1.PingdomContactSpec{
+				Name:   "Alice",
+				Paused: true,
+				Email:  []v1alpha1.EmailTarget{{Address: "alice@example.com"}},
+			},
+		}
+	)
+
+	users.EXPECT().List().Return(nil, nil)
+	users.EXPECT().
+		Create(pingdom.User{Username: "web/Alice [heimdallr]", Paused: "YES"}).
+		Return(&pingdom.UsersResponse{Id: 7}, nil)
+	users.EXPECT().
+		CreateContact(7, pingdom.Contact{Email: "alice@example.com", Severity: "HIGH"}).
+		Return(&pingdom.CreateUserContactResponse{Id: 1}, nil)
+	cli.EXPECT().Users().AnyTimes().Return(users)
+
+	client := &Client{client: cli, logger: zap.NewNop()}
+	require.NoError(t, client.UpdateContact(contact))
+}
+
+func TestUpdateContactUnowned(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		users   = NewMockuserService(ctrl)
+		cli     = NewMockpingdomClient(ctrl)
+		contact = v1alpha1.PingdomContact{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "alice"}}
+	)
+
+	// A user heimdallr didn't create, or one managed by another cluster, isn't changed.
+	users.EXPECT().List().Return([]pingdom.UsersResponse{
+		{Id: 6, Username: "alice"},
+		{Id: 7, Username: "web/alice [heimdallr:us-west-1]"},
+	}, nil)
+	cli.EXPECT().Users().AnyTimes().Return(users)
+
+	client := &Client{client: cli, logger: zap.NewNop()}
+	err := client.UpdateContact(contact)
+	require.IsType(t, &ValidationError{}, err)
+	assert.True(t, IsPermanent(err))
+}
+
+func TestUpdateContactAdopt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		users = NewMockuserService(ctrl)
+		cli   = NewMockpingdomClient(ctrl)
+
+		contact = v1alpha1.PingdomContact{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "alice"},
+			Spec:       v1alpha1.PingdomContactSpec{Adopt: true},
+		}
+	)
+
+	// An adopted user is renamed with the owner marker, so it's managed from then on.
+	users.EXPECT().List().Return([]pingdom.UsersResponse{{Id: 6, Username: "alice"}}, nil)
+	users.EXPECT().Update(6, pingdom.User{Username: "web/alice [heimdallr]", Paused: "NO"}).Return(nil, nil)
+	cli.EXPECT().Users().AnyTimes().Return(users)
+
+	client := &Client{client: cli, logger: zap.NewNop()}
+	require.NoError(t, client.UpdateContact(contact))
+}
+
+func TestDeleteContact(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		users   = NewMockuserService(ctrl)
+		cli     = NewMockpingdomClient(ctrl)
+		contact = v1alpha1.PingdomContact{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "alice"}}
+	)
+
+	// Only the user managed by heimdallr is deleted, not another user of the same name or the
+	// contact of the same name in another namespace.
+	users.EXPECT().List().Return([]pingdom.UsersResponse{
+		{Id: 6, Username: "alice"},
+		{Id: 7, Username: "web/alice [heimdallr]"},
+		{Id: 8, Username: "api/alice [heimdallr]"},
+	}, nil)
+	users.EXPECT().Delete(7).Return(nil, nil)
+	cli.EXPECT().Users().AnyTimes().Return(users)
+
+	client := &Client{client: cli, logger: zap.NewNop()}
+	client.directory.add("contact", "web/alice", 7)
+	require.NoError(t, client.DeleteContact(contact))
+	assert.Empty(t, client.directory.contacts)
+}
+
+func TestUpdateTeam(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		teams = NewMockteamService(ctrl)
+		cli   = NewMockpingdomClient(ctrl)
+
+		team = v1alpha1.PingdomTeam{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "payments"},
+			Spec:       v1alpha1.PingdomTeamSpec{Members: []string{"alice", "bob"}},
+		}
+	)
+
+	teams.EXPECT().List().Return([]pingdom.TeamResponse{{ID: 3, Name: "web/payments [heimdallr:us-east-1]"}}, nil)
+	teams.EXPECT().
+		Update(3, pingdom.TeamData{Name: "web/payments [heimdallr:us-east-1]", UserIds: "1,2"}).
+		Return(&pingdom.TeamResponse{ID: 3}, nil)
+	cli.EXPECT().Teams().AnyTimes().Return(teams)
+
+	client := &Client{client: cli, cluster: "us-east-1", logger: zap.NewNop()}
+	// Members are resolved in the namespace of the team first.
+	client.directory.add("contact", "web/alice", 1)
+	client.directory.add("contact", "alice", 5)
+	client.directory.add("contact", "bob", 2)
+	client.directory.loaded = client.directory.clock()
+	require.NoError(t, client.UpdateTeam(team))
+}
+
+func TestDeleteTeamUnowned(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		teams = NewMockteamService(ctrl)
+		cli   = NewMockpingdomClient(ctrl)
+		team  = v1alpha1.PingdomTeam{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "payments"}}
+	)
+
+	teams.EXPECT().List().Return([]pingdom.TeamResponse{{ID: 3, Name: "payments"}}, nil)
+	cli.EXPECT().Teams().AnyTimes().Return(teams)
+
+	client := &Client{client: cli, logger: zap.NewNop()}
+	require.NoError(t, client.DeleteTeam(team))
+}
+
+func TestUpdateTeamUnresolvedMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	team := v1alpha1.PingdomTeam{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "payments"},
+		Spec:       v1alpha1.PingdomTeamSpec{Members: []string{"carol"}},
+	}
+
+	client := &Client{client: NewMockpingdomClient(ctrl), logger: zap.NewNop()}
+	client.directory.loaded = client.directory.clock()
+	err := client.UpdateTeam(team)
+	assert.Equal(t, &UnresolvedReferenceError{Kind: "contact", Name: "carol"}, err)
+}
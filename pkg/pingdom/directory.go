@@ -0,0 +1,291 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+)
+
+// directoryRefreshInterval is the minimum interval between reloads of the contacts and teams of
+// an account when a name can't be resolved.
+const directoryRefreshInterval = time.Minute
+
+// UnresolvedReferenceError is returned when a check references an integration, contact or team
+// which doesn't exist.
+type UnresolvedReferenceError struct {
+	Kind string
+	Name string
+}
+
+func (e *UnresolvedReferenceError) Error() string {
+	return fmt.Sprintf("%v %q does not exist", e.Kind, e.Name)
+}
+
+// WithIntegrations configures the IDs of the integrations checks can reference by name. Pingdom's
+// API doesn't list integrations, so they can't be looked up like contacts and teams.
+func WithIntegrations(ids map[string]int) Option {
+	return func(c *Client) {
+		c.integrations = ids
+	}
+}
+
+// recipients are the IDs of the users and teams alerted by a check.
+type recipients struct {
+	userIDs []int
+	teamIDs []int
+}
+
+// String returns the sorted IDs of the users and teams, so that recipients can be compared.
+func (r recipients) String() string {
+	users := append([]int(nil), r.userIDs...)
+	teams := append([]int(nil), r.teamIDs...)
+	sort.Ints(users)
+	sort.Ints(teams)
+	return fmt.Sprintf("users=%v teams=%v", users, teams)
+}
+
+// resolve returns the spec with the IDs of the integrations it references by name added to its
+// integration IDs, along with the recipients of its alerts. Checks which don't reference any
+// contacts or teams alert the contacts the client was configured with. Contacts and teams are
+// resolved in the namespace of the check.
+func (c *Client) resolve(namespace string, spec v1alpha1.HTTPCheckSpec) (v1alpha1.HTTPCheckSpec, recipients, error) {
+	rcpts := recipients{userIDs: c.contactIDs}
+	if len(spec.Integrations) > 0 {
+		ids := make([]int, len(spec.IntegrationIDs), len(spec.IntegrationIDs)+len(spec.Integrations))
+		copy(ids, spec.IntegrationIDs)
+		for _, name := range spec.Integrations {
+			id, ok := c.integrations[name]
+			if !ok {
+				return spec, rcpts, &UnresolvedReferenceError{Kind: "integration", Name: name}
+			}
+			ids = append(ids, id)
+		}
+		spec.IntegrationIDs = ids
+	}
+
+	if len(spec.Contacts) == 0 && len(spec.Teams) == 0 {
+		return spec, rcpts, nil
+	}
+
+	var err error
+	if rcpts.userIDs, err = c.directory.resolve(c.client, c.marker(), namespace, "contact", spec.Contacts); err != nil {
+		return spec, rcpts, err
+	}
+	if rcpts.teamIDs, err = c.directory.resolve(c.client, c.marker(), namespace, "team", spec.Teams); err != nil {
+		return spec, rcpts, err
+	}
+	return spec, rcpts, nil
+}
+
+// directory caches the IDs of the contacts and teams of an account by name. It's reloaded when a
+// name can't be found, at most once every directoryRefreshInterval.
+type directory struct {
+	// alerting is set if the contacts and teams are listed with the alerting API of version 3.1,
+	// which token authenticated clients use, rather than as the users and teams of version 2.1.
+	alerting bool
+
+	mu       sync.Mutex
+	contacts map[string]int
+	teams    map[string]int
+	loaded   time.Time
+	now      func() time.Time
+}
+
+// resolve returns the IDs of the contacts or teams with the given names. Contacts and teams whose
+// name ends with the given owner marker can be referenced without it, and those in the given
+// namespace without their namespace.
+func (d *directory) resolve(client pingdomClient, marker, namespace, kind string, names []string) ([]int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var ids []int
+	for _, name := range names {
+		id, ok := d.lookupIn(namespace, kind, name)
+		if !ok && d.stale() {
+			if err := d.load(client, marker); err != nil {
+				return nil, err
+			}
+			id, ok = d.lookupIn(namespace, kind, name)
+		}
+		if !ok {
+			return nil, &UnresolvedReferenceError{Kind: kind, Name: name}
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// lookupIn looks up a name in the given namespace first, so that the contacts and teams managed
+// by heimdallr in the namespace are preferred over those of the same name elsewhere.
+func (d *directory) lookupIn(namespace, kind, name string) (int, bool) {
+	if id, ok := d.lookup(kind, qualifiedName(namespace, name)); ok {
+		return id, true
+	}
+	return d.lookup(kind, name)
+}
+
+func (d *directory) lookup(kind, name string) (int, bool) {
+	if kind == "team" {
+		id, ok := d.teams[name]
+		return id, ok
+	}
+	id, ok := d.contacts[name]
+	return id, ok
+}
+
+// add caches the ID of the contact or team with the given name.
+func (d *directory) add(kind, name string, id int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if kind == "team" {
+		if d.teams == nil {
+			d.teams = make(map[string]int)
+		}
+		d.teams[name] = id
+		return
+	}
+	if d.contacts == nil {
+		d.contacts = make(map[string]int)
+	}
+	d.contacts[name] = id
+}
+
+// forget removes the contact or team with the given name from the cache.
+func (d *directory) forget(kind, name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if kind == "team" {
+		delete(d.teams, name)
+		return
+	}
+	delete(d.contacts, name)
+}
+
+func (d *directory) stale() bool {
+	return d.loaded.IsZero() || d.clock().Sub(d.loaded) >= directoryRefreshInterval
+}
+
+func (d *directory) clock() time.Time {
+	if d.now != nil {
+		return d.now()
+	}
+	return time.Now()
+}
+
+// load reloads the contacts and teams of the account. Contacts can be referenced by their name
+// or any of their email addresses. Contacts and teams managed by heimdallr can also be referenced
+// by their name without the owner marker, and are preferred over those of the same name which
+// aren't.
+func (d *directory) load(client pingdomClient, marker string) error {
+	contacts, teams, err := d.list(client)
+	if err != nil {
+		return err
+	}
+
+	suffix := " " + marker
+	d.contacts = make(map[string]int, len(contacts))
+	for _, contact := range contacts {
+		d.contacts[contact.name] = contact.id
+		for _, email := range contact.emails {
+			d.contacts[email] = contact.id
+		}
+	}
+	for _, contact := range contacts {
+		if strings.HasSuffix(contact.name, suffix) {
+			d.contacts[strings.TrimSuffix(contact.name, suffix)] = contact.id
+		}
+	}
+
+	d.teams = make(map[string]int, len(teams))
+	for _, team := range teams {
+		d.teams[team.name] = team.id
+	}
+	for _, team := range teams {
+		if strings.HasSuffix(team.name, suffix) {
+			d.teams[strings.TrimSuffix(team.name, suffix)] = team.id
+		}
+	}
+	d.loaded = d.clock()
+	return nil
+}
+
+// directoryEntry is a contact or team of an account.
+type directoryEntry struct {
+	id     int
+	name   string
+	emails []string
+}
+
+// list returns the contacts and teams of the account, from the alerting API if the directory
+// uses it and otherwise from the users and teams of the account.
+func (d *directory) list(client pingdomClient) (contacts, teams []directoryEntry, err error) {
+	if d.alerting {
+		alertingContacts, err := client.Alerting().Contacts()
+		if err != nil {
+			return nil, nil, classify("get list of contacts for account", err)
+		}
+		alertingTeams, err := client.Alerting().Teams()
+		if err != nil {
+			return nil, nil, classify("get list of teams for account", err)
+		}
+
+		for _, contact := range alertingContacts {
+			entry := directoryEntry{id: contact.ID, name: contact.Name}
+			for _, email := range contact.NotificationTargets.Email {
+				entry.emails = append(entry.emails, email.Address)
+			}
+			contacts = append(contacts, entry)
+		}
+		for _, team := range alertingTeams {
+			teams = append(teams, directoryEntry{id: team.ID, name: team.Name})
+		}
+		return contacts, teams, nil
+	}
+
+	users, err := client.Users().List()
+	if err != nil {
+		return nil, nil, classify("get list of users for account", err)
+	}
+	pingdomTeams, err := client.Teams().List()
+	if err != nil {
+		return nil, nil, classify("get list of teams for account", err)
+	}
+
+	for _, user := range users {
+		entry := directoryEntry{id: user.Id, name: user.Username}
+		for _, email := range user.Email {
+			entry.emails = append(entry.emails, email.Address)
+		}
+		contacts = append(contacts, entry)
+	}
+	for _, team := range pingdomTeams {
+		teams = append(teams, directoryEntry{id: team.ID, name: team.Name})
+	}
+	return contacts, teams, nil
+}
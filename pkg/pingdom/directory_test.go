@@ -0,0 +1,131 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/golang/mock/gomock"
+	"github.com/russellcardullo/go-pingdom/pingdom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		users = NewMockuserService(ctrl)
+		teams = NewMockteamService(ctrl)
+		cli   = NewMockpingdomClient(ctrl)
+		now   = time.Now()
+	)
+
+	users.EXPECT().List().Times(2).Return([]pingdom.UsersResponse{
+		{
+			Id:       1,
+			Username: "Alice",
+			Email:    []pingdom.UserEmailResponse{{Address: "alice@example.com"}},
+		},
+	}, nil)
+	teams.EXPECT().List().Times(2).Return([]pingdom.TeamResponse{{ID: 2, Name: "payments"}}, nil)
+	cli.EXPECT().Users().Times(2).Return(users)
+	cli.EXPECT().Teams().Times(2).Return(teams)
+
+	client := &Client{
+		client:       cli,
+		contactIDs:   []int{9},
+		integrations: map[string]int{"slack": 3},
+		directory:    directory{now: func() time.Time { return now }},
+	}
+
+	// Checks which don't reference contacts or teams alert the configured contacts.
+	spec, rcpts, err := client.resolve("web", v1alpha1.HTTPCheckSpec{
+		IntegrationIDs: []int{4},
+		Integrations:   []string{"slack"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{4, 3}, spec.IntegrationIDs)
+	assert.Equal(t, recipients{userIDs: []int{9}}, rcpts)
+
+	_, rcpts, err = client.resolve("web", v1alpha1.HTTPCheckSpec{
+		Contacts: []string{"Alice", "alice@example.com"},
+		Teams:    []string{"payments"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, recipients{userIDs: []int{1, 1}, teamIDs: []int{2}}, rcpts)
+
+	// Unknown names are an error, reloading the directory at most once per interval.
+	_, _, err = client.resolve("web", v1alpha1.HTTPCheckSpec{Integrations: []string{"pagerduty"}})
+	assert.Equal(t, &UnresolvedReferenceError{Kind: "integration", Name: "pagerduty"}, err)
+
+	_, _, err = client.resolve("web", v1alpha1.HTTPCheckSpec{Teams: []string{"search"}})
+	assert.Equal(t, &UnresolvedReferenceError{Kind: "team", Name: "search"}, err)
+
+	now = now.Add(directoryRefreshInterval)
+	_, _, err = client.resolve("web", v1alpha1.HTTPCheckSpec{Contacts: []string{"bob"}})
+	assert.Equal(t, &UnresolvedReferenceError{Kind: "contact", Name: "bob"}, err)
+}
+
+func TestResolveOwned(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		users = NewMockuserService(ctrl)
+		teams = NewMockteamService(ctrl)
+		cli   = NewMockpingdomClient(ctrl)
+	)
+
+	users.EXPECT().List().Return([]pingdom.UsersResponse{
+		{Id: 1, Username: "alice"},
+		{Id: 2, Username: "web/alice [heimdallr:us-east-1]"},
+		{Id: 3, Username: "web/bob [heimdallr:us-west-1]"},
+	}, nil)
+	teams.EXPECT().List().Return([]pingdom.TeamResponse{{ID: 4, Name: "web/payments [heimdallr:us-east-1]"}}, nil)
+	cli.EXPECT().Users().Return(users)
+	cli.EXPECT().Teams().Return(teams)
+
+	client := &Client{client: cli, cluster: "us-east-1"}
+
+	// Contacts and teams managed by the client in the namespace of the check are referenced
+	// without their namespace and the owner marker, and are preferred over those of the same name
+	// it doesn't manage.
+	_, rcpts, err := client.resolve("web", v1alpha1.HTTPCheckSpec{
+		Contacts: []string{"alice"},
+		Teams:    []string{"payments"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, recipients{userIDs: []int{2}, teamIDs: []int{4}}, rcpts)
+
+	// Checks in other namespaces reference them with their namespace.
+	_, rcpts, err = client.resolve("api", v1alpha1.HTTPCheckSpec{Contacts: []string{"alice", "web/alice"}})
+	require.NoError(t, err)
+	assert.Equal(t, recipients{userIDs: []int{1, 2}}, rcpts)
+
+	// Those managed by another cluster have to be referenced by their full name.
+	_, _, err = client.resolve("web", v1alpha1.HTTPCheckSpec{Contacts: []string{"bob"}})
+	assert.Equal(t, &UnresolvedReferenceError{Kind: "contact", Name: "bob"}, err)
+}
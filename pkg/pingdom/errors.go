@@ -0,0 +1,195 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/russellcardullo/go-pingdom/pingdom"
+)
+
+// NotFoundError is returned when a check doesn't exist in Pingdom, such as when it was deleted
+// outside of heimdallr.
+type NotFoundError struct {
+	Op      string
+	Message string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("failed to %v: %v", e.Op, e.Message)
+}
+
+// ValidationError is returned when the settings of a check are invalid. Field is the field of the
+// check's spec which was rejected, if it's known.
+type ValidationError struct {
+	Op      string
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("failed to %v: %v", e.Op, e.Message)
+	}
+	return fmt.Sprintf("failed to %v: invalid %v: %v", e.Op, e.Field, e.Message)
+}
+
+// RateLimitedError is returned when Pingdom rejects a request for exceeding its rate limits,
+// even after it was retried.
+type RateLimitedError struct {
+	Op      string
+	Message string
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("failed to %v: rate limited: %v", e.Op, e.Message)
+}
+
+// AuthError is returned when Pingdom rejects the credentials of the client. It isn't permanent,
+// since the request may succeed once the credentials are reloaded, such as while a key is being
+// rotated.
+type AuthError struct {
+	Op      string
+	Message string
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("failed to %v: unauthorized: %v", e.Op, e.Message)
+}
+
+// TransientError is returned when a request to Pingdom fails in a way that may succeed if it's
+// retried, such as a network error or a server error.
+type TransientError struct {
+	Op  string
+	Err error
+}
+
+func (e *TransientError) Error() string {
+	return fmt.Sprintf("failed to %v: %v", e.Op, e.Err)
+}
+
+// IsNotFound returns whether the error is a NotFoundError.
+func IsNotFound(err error) bool {
+	_, ok := err.(*NotFoundError)
+	return ok
+}
+
+// IsUnauthorized returns whether the error is an AuthError.
+func IsUnauthorized(err error) bool {
+	_, ok := err.(*AuthError)
+	return ok
+}
+
+// IsPermanent returns whether the error will recur if the request is retried unchanged, so it
+// shouldn't be retried.
+func IsPermanent(err error) bool {
+	switch err.(type) {
+	case *NotFoundError, *ValidationError:
+		return true
+	default:
+		return false
+	}
+}
+
+// validPattern matches the errors the Pingdom client returns for checks it considers invalid
+// before making a request, such as "Invalid value for `Hostname`. Must contain non-empty string".
+var validPattern = regexp.MustCompile("^Invalid value.* for `(\\w+)`")
+
+// paramPattern matches the parameter named in the messages of requests Pingdom rejects as
+// invalid, such as "Invalid parameter value => resolution".
+var paramPattern = regexp.MustCompile(`=>\s*(\w+)`)
+
+// specFields maps the parameters of Pingdom checks to the fields of the spec they're set from.
+var specFields = map[string]string{
+	"hostname":                 "hostname",
+	"host":                     "hostname",
+	"url":                      "url",
+	"port":                     "port",
+	"resolution":               "intervalMinutes",
+	"sendnotificationwhendown": "triggerThreshold",
+	"notifyagainevery":         "retriggerThreshold",
+	"notifywhenbackup":         "notifyWhenBackup",
+	"encryption":               "enableTLS",
+	"integrationids":           "integrationIDs",
+	"userids":                  "contacts",
+	"teamids":                  "teams",
+	"tags":                     "tags",
+	"name":                     "nameTemplate",
+}
+
+// classify returns the typed error for an error returned by the Pingdom client while performing
+// the given operation.
+func classify(op string, err error) error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *pingdom.PingdomError:
+		return classifyResponse(op, e)
+	case net.Error:
+		return &TransientError{Op: op, Err: err}
+	}
+
+	if m := validPattern.FindStringSubmatch(err.Error()); m != nil {
+		return &ValidationError{Op: op, Field: specField(m[1]), Message: err.Error()}
+	}
+	return &TransientError{Op: op, Err: err}
+}
+
+func classifyResponse(op string, e *pingdom.PingdomError) error {
+	msg := e.Message
+	if msg == "" {
+		msg = e.StatusDesc
+	}
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+
+	switch code := e.StatusCode; {
+	case code == http.StatusNotFound:
+		return &NotFoundError{Op: op, Message: msg}
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return &AuthError{Op: op, Message: msg}
+	case code == http.StatusTooManyRequests:
+		return &RateLimitedError{Op: op, Message: msg}
+	case code >= 400 && code < 500:
+		var field string
+		if m := paramPattern.FindStringSubmatch(msg); m != nil {
+			field = specField(m[1])
+		}
+		return &ValidationError{Op: op, Field: field, Message: msg}
+	default:
+		return &TransientError{Op: op, Err: e}
+	}
+}
+
+// specField returns the field of the spec a Pingdom parameter is set from, or the parameter
+// itself if it doesn't correspond to one.
+func specField(param string) string {
+	param = strings.ToLower(param)
+	if field, ok := specFields[param]; ok {
+		return field
+	}
+	return param
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/russellcardullo/go-pingdom/pingdom"
+	"github.com/stretchr/testify/assert"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassify(t *testing.T) {
+	tests := map[string]struct {
+		err       error
+		expected  error
+		permanent bool
+	}{
+		"not found": {
+			err:       &pingdom.PingdomError{StatusCode: 404, StatusDesc: "Not Found", Message: "Check not found"},
+			expected:  &NotFoundError{Op: "update check", Message: "Check not found"},
+			permanent: true,
+		},
+		"invalid parameter": {
+			err: &pingdom.PingdomError{StatusCode: 400, StatusDesc: "Bad Request", Message: "Invalid parameter value => resolution"},
+			expected: &ValidationError{
+				Op:      "update check",
+				Field:   "intervalMinutes",
+				Message: "Invalid parameter value => resolution",
+			},
+			permanent: true,
+		},
+		"bad request": {
+			err:       &pingdom.PingdomError{StatusCode: 400, StatusDesc: "Bad Request"},
+			expected:  &ValidationError{Op: "update check", Message: "Bad Request"},
+			permanent: true,
+		},
+		"invalid check": {
+			err: errors.New("Invalid value for `Hostname`.  Must contain non-empty string"),
+			expected: &ValidationError{
+				Op:      "update check",
+				Field:   "hostname",
+				Message: "Invalid value for `Hostname`.  Must contain non-empty string",
+			},
+			permanent: true,
+		},
+		"unauthorized": {
+			err:      &pingdom.PingdomError{StatusCode: 401, Message: "Invalid token"},
+			expected: &AuthError{Op: "update check", Message: "Invalid token"},
+		},
+		"forbidden": {
+			err:      &pingdom.PingdomError{StatusCode: 403},
+			expected: &AuthError{Op: "update check", Message: "Forbidden"},
+		},
+		"rate limited": {
+			err:      &pingdom.PingdomError{StatusCode: 429, Message: "Too many requests"},
+			expected: &RateLimitedError{Op: "update check", Message: "Too many requests"},
+		},
+		"server error": {
+			err:      &pingdom.PingdomError{StatusCode: 503},
+			expected: &TransientError{Op: "update check", Err: &pingdom.PingdomError{StatusCode: 503}},
+		},
+		"network error": {
+			err: &url.Error{Op: "Put", URL: "https://api.pingdom.com", Err: timeoutError{}},
+			expected: &TransientError{
+				Op:  "update check",
+				Err: &url.Error{Op: "Put", URL: "https://api.pingdom.com", Err: timeoutError{}},
+			},
+		},
+		"unknown error": {
+			err:      errors.New("unexpected EOF"),
+			expected: &TransientError{Op: "update check", Err: errors.New("unexpected EOF")},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := classify("update check", tt.err)
+			assert.Equal(t, tt.expected, err)
+			assert.Equal(t, tt.permanent, IsPermanent(err))
+		})
+	}
+
+	assert.NoError(t, classify("update check", nil))
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	err := &ValidationError{Op: "create check", Field: "hostname", Message: "Invalid hostname"}
+	assert.EqualError(t, err, "failed to create check: invalid hostname: Invalid hostname")
+
+	err = &ValidationError{Op: "create check", Message: "Bad Request"}
+	assert.EqualError(t, err, "failed to create check: Bad Request")
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/russellcardullo/go-pingdom/pingdom"
+)
+
+// checkPageSize is the number of checks listed per request, the most Pingdom allows.
+var checkPageSize = 25000
+
+// detailReadConcurrency is the number of checks whose details are read from Pingdom at once.
+const detailReadConcurrency = 8
+
+// listChecks lists the checks matching the given parameters, a page at a time, until Pingdom
+// returns a page which isn't full.
+func (c *Client) listChecks(params map[string]string) ([]pingdom.CheckResponse, error) {
+	var checks []pingdom.CheckResponse
+	for offset := 0; ; offset += checkPageSize {
+		page := map[string]string{
+			"limit":  strconv.Itoa(checkPageSize),
+			"offset": strconv.Itoa(offset),
+		}
+		for k, v := range params {
+			page[k] = v
+		}
+
+		list, err := c.client.Checks().List(page)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, list...)
+		if len(list) < checkPageSize {
+			return checks, nil
+		}
+	}
+}
+
+// readHTTPChecks reads the details of the given checks, which the list of checks doesn't include,
+// with at most detailReadConcurrency reads in flight. The checks are returned in the same order.
+func (c *Client) readHTTPChecks(list []pingdom.CheckResponse) ([]httpCheck, error) {
+	var (
+		checks = make([]httpCheck, len(list))
+		errs   = make([]error, len(list))
+		sem    = make(chan struct{}, detailReadConcurrency)
+		wg     sync.WaitGroup
+	)
+	for i, cr := range list {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, cr pingdom.CheckResponse) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			checks[i], errs[i] = c.readHTTPCheck(cr.ID)
+		}(i, cr)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return checks, nil
+}
+
+// listedHTTPCheck returns the state of a check from the fields the list of checks includes,
+// without reading its details.
+func listedHTTPCheck(cr pingdom.CheckResponse) httpCheck {
+	var typ v1alpha1.CheckType
+	if cr.Type.Name == string(v1alpha1.CheckTypeTCP) {
+		typ = v1alpha1.CheckTypeTCP
+	}
+
+	return httpCheck{
+		id:   cr.ID,
+		name: cr.Name,
+		spec: v1alpha1.HTTPCheckSpec{
+			Type:            typ,
+			Hostname:        cr.Hostname,
+			IntervalMinutes: cr.Resolution,
+			Tags:            readUserTags(&cr),
+			Paused:          cr.Status == "paused",
+		},
+		listed: true,
+	}
+}
+
+// withListedFields returns the spec with the fields the list of checks includes replaced by those
+// of the listed spec, so that a listed check is only compared on the fields which are known.
+func withListedFields(spec, listed v1alpha1.HTTPCheckSpec) v1alpha1.HTTPCheckSpec {
+	spec.Type = listed.Type
+	spec.Hostname = listed.Hostname
+	spec.IntervalMinutes = listed.IntervalMinutes
+	spec.Tags = listed.Tags
+	spec.Paused = listed.Paused
+	return spec
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/russellcardullo/go-pingdom/pingdom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestListChecksPaginates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	defer func(size int) { checkPageSize = size }(checkPageSize)
+	checkPageSize = 2
+
+	var (
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+	)
+	gomock.InOrder(
+		checks.EXPECT().
+			List(map[string]string{"tags": heimdallrTag, "limit": "2", "offset": "0"}).
+			Return([]pingdom.CheckResponse{{ID: 1}, {ID: 2}}, nil),
+		checks.EXPECT().
+			List(map[string]string{"tags": heimdallrTag, "limit": "2", "offset": "2"}).
+			Return([]pingdom.CheckResponse{{ID: 3}, {ID: 4}}, nil),
+		checks.EXPECT().
+			List(map[string]string{"tags": heimdallrTag, "limit": "2", "offset": "4"}).
+			Return([]pingdom.CheckResponse{{ID: 5}}, nil),
+	)
+	cli.EXPECT().Checks().Times(3).Return(checks)
+
+	client := Client{client: cli, logger: zap.NewNop()}
+	list, err := client.listChecks(map[string]string{"tags": heimdallrTag})
+	require.NoError(t, err)
+
+	var ids []int
+	for _, cr := range list {
+		ids = append(ids, cr.ID)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, ids)
+}
+
+func TestListChecksError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+	)
+	checks.EXPECT().List(gomock.Any()).Return(nil, errors.New("unavailable"))
+	cli.EXPECT().Checks().Return(checks)
+
+	client := Client{client: cli, logger: zap.NewNop()}
+	_, err := client.listChecks(nil)
+	assert.Error(t, err)
+}
+
+func TestReadHTTPChecks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+		list   []pingdom.CheckResponse
+	)
+	for id := 1; id <= 2*detailReadConcurrency; id++ {
+		cr := pingdom.CheckResponse{ID: id, Hostname: "example.com"}
+		checks.EXPECT().Read(id).Return(&cr, nil)
+		list = append(list, cr)
+	}
+	cli.EXPECT().Checks().Times(len(list)).Return(checks)
+
+	client := Client{client: cli, logger: zap.NewNop()}
+	read, err := client.readHTTPChecks(list)
+	require.NoError(t, err)
+
+	require.Len(t, read, len(list))
+	for i, hc := range read {
+		assert.Equal(t, list[i].ID, hc.id, "expected checks to be returned in order")
+	}
+}
+
+func TestReadHTTPChecksError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+	)
+	checks.EXPECT().Read(1).Return(&pingdom.CheckResponse{ID: 1}, nil)
+	checks.EXPECT().Read(2).Return(nil, errors.New("unavailable"))
+	cli.EXPECT().Checks().Times(2).Return(checks)
+
+	client := Client{client: cli, logger: zap.NewNop()}
+	_, err := client.readHTTPChecks([]pingdom.CheckResponse{{ID: 1}, {ID: 2, Name: "web/bar"}})
+	assert.EqualError(t, err, "failed to read check 2: unavailable")
+}
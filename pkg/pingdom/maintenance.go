@@ -0,0 +1,221 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/russellcardullo/go-pingdom/pingdom"
+	"go.uber.org/zap"
+)
+
+// recurrenceTypes are the ways in which a Pingdom maintenance window can repeat.
+var recurrenceTypes = map[string]bool{
+	"day":   true,
+	"week":  true,
+	"month": true,
+}
+
+// UpdateMaintenanceWindow updates a Pingdom maintenance window for the given checks, creating it
+// if it does not exist. Windows are identified by a prefix of their description, and a window
+// which selects no checks managed by the client is deleted since Pingdom requires at least one.
+// An existing window is only updated if it differs from the given one.
+func (c *Client) UpdateMaintenanceWindow(window v1alpha1.MaintenanceWindow, checks []v1alpha1.HTTPCheck) error {
+	mw, err := c.toMaintenanceWindow(window, checks)
+	if err != nil {
+		return err
+	}
+	if mw.UptimeIDs == "" {
+		c.logger.Info("maintenance window selects no checks", zap.String("name", getWindowName(window)))
+		return c.DeleteMaintenanceWindow(window)
+	}
+
+	existing, found, err := c.findMaintenanceWindow(window)
+	if err != nil {
+		return err
+	}
+
+	if found && maintenanceMatches(existing, mw) {
+		return nil
+	}
+
+	if c.dryRun {
+		c.logger.Info(
+			"dry run: would update maintenance window",
+			zap.String("name", getWindowName(window)),
+			zap.Bool("exists", found),
+			zap.String("checks", mw.UptimeIDs),
+		)
+		return nil
+	}
+
+	if found {
+		if _, err := c.client.Maintenances().Update(existing.ID, &mw); err != nil {
+			return classify("update maintenance window", err)
+		}
+	} else {
+		if _, err := c.client.Maintenances().Create(&mw); err != nil {
+			return classify("create maintenance window", err)
+		}
+	}
+	c.logger.Info("successfully updated maintenance window", zap.String("name", getWindowName(window)))
+	return nil
+}
+
+// DeleteMaintenanceWindow deletes a Pingdom maintenance window.
+func (c *Client) DeleteMaintenanceWindow(window v1alpha1.MaintenanceWindow) error {
+	existing, found, err := c.findMaintenanceWindow(window)
+	if err != nil || !found {
+		return err
+	}
+
+	if c.dryRun {
+		c.logger.Info("dry run: would delete maintenance window", zap.String("name", getWindowName(window)))
+		return nil
+	}
+	if _, err := c.client.Maintenances().Delete(existing.ID); err != nil {
+		return classify("delete maintenance window", err)
+	}
+	c.logger.Info("successfully deleted maintenance window", zap.String("name", getWindowName(window)))
+	return nil
+}
+
+// toMaintenanceWindow converts a window to a Pingdom maintenance window covering those of the
+// given checks which the client manages.
+func (c *Client) toMaintenanceWindow(window v1alpha1.MaintenanceWindow, checks []v1alpha1.HTTPCheck) (pingdom.MaintenanceWindow, error) {
+	spec := window.Spec
+	if !spec.End.After(spec.Start.Time) {
+		return pingdom.MaintenanceWindow{}, &ValidationError{
+			Op:      "update maintenance window",
+			Field:   "end",
+			Message: "maintenance window must end after it starts",
+		}
+	}
+
+	var ids []int
+	for _, check := range checks {
+		// Checks which haven't been created yet, or were only created in dry run mode, have no ID.
+		if hc, ok := c.lookup(c.ownerTag(check)); ok && hc.id != 0 {
+			ids = append(ids, hc.id)
+		}
+	}
+	sort.Ints(ids)
+
+	uptimeIDs := make([]string, len(ids))
+	for i, id := range ids {
+		uptimeIDs[i] = strconv.Itoa(id)
+	}
+
+	mw := pingdom.MaintenanceWindow{
+		Description:    c.maintenanceDescription(window),
+		From:           spec.Start.Unix(),
+		To:             spec.End.Unix(),
+		RecurrenceType: "none",
+		UptimeIDs:      strings.Join(uptimeIDs, ","),
+	}
+
+	if r := spec.Recurrence; r != nil {
+		if !recurrenceTypes[r.Type] {
+			return pingdom.MaintenanceWindow{}, &ValidationError{
+				Op:      "update maintenance window",
+				Field:   "recurrence.type",
+				Message: fmt.Sprintf("unknown recurrence type %q", r.Type),
+			}
+		}
+		if !r.Until.After(spec.End.Time) {
+			return pingdom.MaintenanceWindow{}, &ValidationError{
+				Op:      "update maintenance window",
+				Field:   "recurrence.until",
+				Message: "maintenance window must repeat until after it first ends",
+			}
+		}
+
+		mw.RecurrenceType = r.Type
+		mw.RepeatEvery = r.RepeatEvery
+		if mw.RepeatEvery == 0 {
+			mw.RepeatEvery = 1
+		}
+		mw.EffectiveTo = r.Until.Unix()
+	}
+	return mw, nil
+}
+
+func (c *Client) findMaintenanceWindow(window v1alpha1.MaintenanceWindow) (pingdom.MaintenanceResponse, bool, error) {
+	windows, err := c.client.Maintenances().List()
+	if err != nil {
+		return pingdom.MaintenanceResponse{}, false, classify("get list of maintenance windows for account", err)
+	}
+
+	prefix := c.maintenancePrefix(window)
+	for _, mw := range windows {
+		if strings.HasPrefix(mw.Description, prefix) {
+			return mw, true, nil
+		}
+	}
+	return pingdom.MaintenanceResponse{}, false, nil
+}
+
+// maintenanceMatches returns whether an existing Pingdom maintenance window already matches the
+// given one, so that it doesn't need to be updated.
+func maintenanceMatches(existing pingdom.MaintenanceResponse, mw pingdom.MaintenanceWindow) bool {
+	ids := append([]int(nil), existing.Checks.Uptime...)
+	sort.Ints(ids)
+	uptimeIDs := make([]string, len(ids))
+	for i, id := range ids {
+		uptimeIDs[i] = strconv.Itoa(id)
+	}
+
+	return existing.Description == mw.Description &&
+		existing.From == mw.From &&
+		existing.To == mw.To &&
+		existing.RecurrenceType == mw.RecurrenceType &&
+		existing.RepeatEvery == mw.RepeatEvery &&
+		existing.EffectiveTo == mw.EffectiveTo &&
+		strings.Join(uptimeIDs, ",") == mw.UptimeIDs
+}
+
+// maintenanceDescription returns the description of the Pingdom maintenance window for the given
+// window, which starts with a prefix identifying the window.
+func (c *Client) maintenanceDescription(window v1alpha1.MaintenanceWindow) string {
+	if window.Spec.Description == "" {
+		return c.maintenancePrefix(window)
+	}
+	return c.maintenancePrefix(window) + " " + window.Spec.Description
+}
+
+// maintenancePrefix returns the prefix of the description identifying the Pingdom maintenance
+// window of the given window.
+func (c *Client) maintenancePrefix(window v1alpha1.MaintenanceWindow) string {
+	name := getWindowName(window)
+	if c.cluster != "" {
+		name = c.cluster + "/" + name
+	}
+	return "[" + heimdallrTag + " " + name + "]"
+}
+
+func getWindowName(window v1alpha1.MaintenanceWindow) string {
+	return window.Namespace + "/" + window.Name
+}
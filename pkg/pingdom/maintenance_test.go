@@ -0,0 +1,246 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/golang/mock/gomock"
+	"github.com/russellcardullo/go-pingdom/pingdom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	windowStart = time.Date(2018, time.October, 1, 22, 0, 0, 0, time.UTC)
+	windowEnd   = windowStart.Add(time.Hour)
+)
+
+func newTestWindow() v1alpha1.MaintenanceWindow {
+	return v1alpha1.MaintenanceWindow{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deploy"},
+		Spec: v1alpha1.MaintenanceWindowSpec{
+			Description: "weekly deploy",
+			Start:       metav1.NewTime(windowStart),
+			End:         metav1.NewTime(windowEnd),
+		},
+	}
+}
+
+func newTestWindowChecks(names ...string) []v1alpha1.HTTPCheck {
+	checks := make([]v1alpha1.HTTPCheck, len(names))
+	for i, name := range names {
+		checks[i] = v1alpha1.HTTPCheck{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+	}
+	return checks
+}
+
+func TestUpdateMaintenanceWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		maintenances = NewMockmaintenanceService(ctrl)
+		cli          = NewMockpingdomClient(ctrl)
+		client       = &Client{client: cli, logger: zap.NewNop(), httpChecks: make(map[string]httpCheck)}
+
+		window = newTestWindow()
+		checks = newTestWindowChecks("foo", "bar", "baz")
+	)
+	window.Spec.Recurrence = &v1alpha1.MaintenanceRecurrence{
+		Type:  "week",
+		Until: metav1.NewTime(windowStart.AddDate(0, 3, 0)),
+	}
+
+	// baz hasn't been created in Pingdom yet, so it isn't put into maintenance.
+	client.store(client.ownerTag(checks[0]), httpCheck{id: 2})
+	client.store(client.ownerTag(checks[1]), httpCheck{id: 1})
+
+	cli.EXPECT().Maintenances().AnyTimes().Return(maintenances)
+	maintenances.EXPECT().List().Return([]pingdom.MaintenanceResponse{
+		{ID: 3, Description: "unrelated"},
+		{ID: 4, Description: "[managed-by-heimdallr default/deploy] old description"},
+	}, nil)
+	maintenances.EXPECT().Update(4, &pingdom.MaintenanceWindow{
+		Description:    "[managed-by-heimdallr default/deploy] weekly deploy",
+		From:           windowStart.Unix(),
+		To:             windowEnd.Unix(),
+		RecurrenceType: "week",
+		RepeatEvery:    1,
+		EffectiveTo:    windowStart.AddDate(0, 3, 0).Unix(),
+		UptimeIDs:      "1,2",
+	}).Return(nil, nil)
+
+	require.NoError(t, client.UpdateMaintenanceWindow(window, checks))
+}
+
+func TestUpdateMaintenanceWindowUnchanged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		maintenances = NewMockmaintenanceService(ctrl)
+		cli          = NewMockpingdomClient(ctrl)
+		client       = &Client{client: cli, logger: zap.NewNop(), httpChecks: make(map[string]httpCheck)}
+
+		checks = newTestWindowChecks("foo", "bar")
+	)
+	client.store(client.ownerTag(checks[0]), httpCheck{id: 2})
+	client.store(client.ownerTag(checks[1]), httpCheck{id: 1})
+
+	// The window already matches, so it isn't updated.
+	cli.EXPECT().Maintenances().AnyTimes().Return(maintenances)
+	maintenances.EXPECT().List().Return([]pingdom.MaintenanceResponse{
+		{
+			ID:             4,
+			Description:    "[managed-by-heimdallr default/deploy] weekly deploy",
+			From:           windowStart.Unix(),
+			To:             windowEnd.Unix(),
+			RecurrenceType: "none",
+			Checks:         pingdom.MaintenanceCheckResponse{Uptime: []int{2, 1}},
+		},
+	}, nil)
+
+	require.NoError(t, client.UpdateMaintenanceWindow(newTestWindow(), checks))
+}
+
+func TestCreateMaintenanceWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		maintenances = NewMockmaintenanceService(ctrl)
+		cli          = NewMockpingdomClient(ctrl)
+		client       = &Client{
+			client:     cli,
+			cluster:    "east",
+			logger:     zap.NewNop(),
+			httpChecks: make(map[string]httpCheck),
+		}
+
+		window = newTestWindow()
+		checks = newTestWindowChecks("foo")
+	)
+	client.store(client.ownerTag(checks[0]), httpCheck{id: 1})
+
+	// A window of the same object in another cluster isn't this cluster's window.
+	cli.EXPECT().Maintenances().AnyTimes().Return(maintenances)
+	maintenances.EXPECT().List().Return([]pingdom.MaintenanceResponse{
+		{ID: 4, Description: "[managed-by-heimdallr west/default/deploy] weekly deploy"},
+	}, nil)
+	maintenances.EXPECT().Create(&pingdom.MaintenanceWindow{
+		Description:    "[managed-by-heimdallr east/default/deploy] weekly deploy",
+		From:           windowStart.Unix(),
+		To:             windowEnd.Unix(),
+		RecurrenceType: "none",
+		UptimeIDs:      "1",
+	}).Return(&pingdom.MaintenanceResponse{ID: 5}, nil)
+
+	require.NoError(t, client.UpdateMaintenanceWindow(window, checks))
+}
+
+func TestUpdateMaintenanceWindowWithoutChecks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		maintenances = NewMockmaintenanceService(ctrl)
+		cli          = NewMockpingdomClient(ctrl)
+		client       = &Client{client: cli, logger: zap.NewNop(), httpChecks: make(map[string]httpCheck)}
+	)
+
+	// Pingdom requires windows to cover a check, so the existing window is deleted instead.
+	cli.EXPECT().Maintenances().AnyTimes().Return(maintenances)
+	maintenances.EXPECT().List().Return([]pingdom.MaintenanceResponse{
+		{ID: 4, Description: "[managed-by-heimdallr default/deploy]"},
+	}, nil)
+	maintenances.EXPECT().Delete(4).Return(nil, nil)
+
+	require.NoError(t, client.UpdateMaintenanceWindow(newTestWindow(), newTestWindowChecks("foo")))
+}
+
+func TestInvalidMaintenanceWindow(t *testing.T) {
+	client := &Client{logger: zap.NewNop(), httpChecks: make(map[string]httpCheck)}
+
+	tests := []struct {
+		name   string
+		modify func(*v1alpha1.MaintenanceWindow)
+		field  string
+	}{
+		{
+			name: "ends before start",
+			modify: func(w *v1alpha1.MaintenanceWindow) {
+				w.Spec.End = metav1.NewTime(windowStart.Add(-time.Hour))
+			},
+			field: "end",
+		},
+		{
+			name: "unknown recurrence",
+			modify: func(w *v1alpha1.MaintenanceWindow) {
+				w.Spec.Recurrence = &v1alpha1.MaintenanceRecurrence{Type: "year"}
+			},
+			field: "recurrence.type",
+		},
+		{
+			name: "recurrence ends before window",
+			modify: func(w *v1alpha1.MaintenanceWindow) {
+				w.Spec.Recurrence = &v1alpha1.MaintenanceRecurrence{Type: "day", Until: metav1.NewTime(windowStart)}
+			},
+			field: "recurrence.until",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window := newTestWindow()
+			tt.modify(&window)
+			// Invalid windows aren't retried until they change.
+			err := client.UpdateMaintenanceWindow(window, nil)
+			require.IsType(t, &ValidationError{}, err)
+			assert.Equal(t, tt.field, err.(*ValidationError).Field)
+			assert.True(t, IsPermanent(err))
+		})
+	}
+}
+
+func TestDeleteMaintenanceWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		maintenances = NewMockmaintenanceService(ctrl)
+		cli          = NewMockpingdomClient(ctrl)
+		client       = &Client{client: cli, logger: zap.NewNop()}
+	)
+
+	cli.EXPECT().Maintenances().AnyTimes().Return(maintenances)
+	maintenances.EXPECT().List().Return([]pingdom.MaintenanceResponse{
+		{ID: 4, Description: "[managed-by-heimdallr default/other]"},
+	}, nil)
+
+	// Windows which don't exist in Pingdom are ignored.
+	require.NoError(t, client.DeleteMaintenanceWindow(newTestWindow()))
+}
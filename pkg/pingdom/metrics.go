@@ -0,0 +1,71 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var operations = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "heimdallr",
+		Subsystem: "pingdom",
+		Name:      "operations_total",
+		Help:      "Number of create, update and delete operations performed on Pingdom checks.",
+	},
+	[]string{"action", "dry_run"},
+)
+
+var rateLimited = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "heimdallr",
+		Subsystem: "pingdom",
+		Name:      "rate_limited_total",
+		Help:      "Number of requests to Pingdom which were rejected for exceeding its rate limits.",
+	},
+)
+
+var recreated = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "heimdallr",
+		Subsystem: "pingdom",
+		Name:      "checks_recreated_total",
+		Help:      "Number of checks recreated after they were deleted from Pingdom outside of heimdallr.",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(operations, rateLimited, recreated)
+}
+
+func recordOperation(action Action, dryRun bool) {
+	operations.WithLabelValues(string(action), strconv.FormatBool(dryRun)).Inc()
+}
+
+func recordRateLimited() {
+	rateLimited.Inc()
+}
+
+func recordRecreated() {
+	recreated.Inc()
+}
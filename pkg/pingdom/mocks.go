@@ -6,8 +6,10 @@ package pingdom
 
 import (
 	gomock "github.com/golang/mock/gomock"
+	v1alpha1 "github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
 	pingdom "github.com/russellcardullo/go-pingdom/pingdom"
 	reflect "reflect"
+	time "time"
 )
 
 // MockuserService is a mock of userService interface
@@ -46,6 +48,71 @@ func (mr *MockuserServiceMockRecorder) List() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockuserService)(nil).List))
 }
 
+// Create mocks base method
+func (m *MockuserService) Create(user pingdom.User) (*pingdom.UsersResponse, error) {
+	ret := m.ctrl.Call(m, "Create", user)
+	ret0, _ := ret[0].(*pingdom.UsersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockuserServiceMockRecorder) Create(user interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockuserService)(nil).Create), user)
+}
+
+// Update mocks base method
+func (m *MockuserService) Update(id int, user pingdom.User) (*pingdom.PingdomResponse, error) {
+	ret := m.ctrl.Call(m, "Update", id, user)
+	ret0, _ := ret[0].(*pingdom.PingdomResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update
+func (mr *MockuserServiceMockRecorder) Update(id, user interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockuserService)(nil).Update), id, user)
+}
+
+// Delete mocks base method
+func (m *MockuserService) Delete(id int) (*pingdom.PingdomResponse, error) {
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(*pingdom.PingdomResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockuserServiceMockRecorder) Delete(id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockuserService)(nil).Delete), id)
+}
+
+// CreateContact mocks base method
+func (m *MockuserService) CreateContact(userID int, contact pingdom.Contact) (*pingdom.CreateUserContactResponse, error) {
+	ret := m.ctrl.Call(m, "CreateContact", userID, contact)
+	ret0, _ := ret[0].(*pingdom.CreateUserContactResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateContact indicates an expected call of CreateContact
+func (mr *MockuserServiceMockRecorder) CreateContact(userID, contact interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateContact", reflect.TypeOf((*MockuserService)(nil).CreateContact), userID, contact)
+}
+
+// DeleteContact mocks base method
+func (m *MockuserService) DeleteContact(userID, contactID int) (*pingdom.PingdomResponse, error) {
+	ret := m.ctrl.Call(m, "DeleteContact", userID, contactID)
+	ret0, _ := ret[0].(*pingdom.PingdomResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteContact indicates an expected call of DeleteContact
+func (mr *MockuserServiceMockRecorder) DeleteContact(userID, contactID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteContact", reflect.TypeOf((*MockuserService)(nil).DeleteContact), userID, contactID)
+}
+
 // MockcheckService is a mock of checkService interface
 type MockcheckService struct {
 	ctrl     *gomock.Controller
@@ -138,6 +205,258 @@ func (mr *MockcheckServiceMockRecorder) List(params ...interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockcheckService)(nil).List), params...)
 }
 
+// MockteamService is a mock of teamService interface
+type MockteamService struct {
+	ctrl     *gomock.Controller
+	recorder *MockteamServiceMockRecorder
+}
+
+// MockteamServiceMockRecorder is the mock recorder for MockteamService
+type MockteamServiceMockRecorder struct {
+	mock *MockteamService
+}
+
+// NewMockteamService creates a new mock instance
+func NewMockteamService(ctrl *gomock.Controller) *MockteamService {
+	mock := &MockteamService{ctrl: ctrl}
+	mock.recorder = &MockteamServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockteamService) EXPECT() *MockteamServiceMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method
+func (m *MockteamService) List() ([]pingdom.TeamResponse, error) {
+	ret := m.ctrl.Call(m, "List")
+	ret0, _ := ret[0].([]pingdom.TeamResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockteamServiceMockRecorder) List() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockteamService)(nil).List))
+}
+
+// Create mocks base method
+func (m *MockteamService) Create(team pingdom.TeamData) (*pingdom.TeamResponse, error) {
+	ret := m.ctrl.Call(m, "Create", team)
+	ret0, _ := ret[0].(*pingdom.TeamResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockteamServiceMockRecorder) Create(team interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockteamService)(nil).Create), team)
+}
+
+// Update mocks base method
+func (m *MockteamService) Update(id int, team pingdom.TeamData) (*pingdom.TeamResponse, error) {
+	ret := m.ctrl.Call(m, "Update", id, team)
+	ret0, _ := ret[0].(*pingdom.TeamResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update
+func (mr *MockteamServiceMockRecorder) Update(id, team interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockteamService)(nil).Update), id, team)
+}
+
+// Delete mocks base method
+func (m *MockteamService) Delete(id int) (*pingdom.TeamDeleteResponse, error) {
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(*pingdom.TeamDeleteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockteamServiceMockRecorder) Delete(id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockteamService)(nil).Delete), id)
+}
+
+// MockmaintenanceService is a mock of maintenanceService interface
+type MockmaintenanceService struct {
+	ctrl     *gomock.Controller
+	recorder *MockmaintenanceServiceMockRecorder
+}
+
+// MockmaintenanceServiceMockRecorder is the mock recorder for MockmaintenanceService
+type MockmaintenanceServiceMockRecorder struct {
+	mock *MockmaintenanceService
+}
+
+// NewMockmaintenanceService creates a new mock instance
+func NewMockmaintenanceService(ctrl *gomock.Controller) *MockmaintenanceService {
+	mock := &MockmaintenanceService{ctrl: ctrl}
+	mock.recorder = &MockmaintenanceServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockmaintenanceService) EXPECT() *MockmaintenanceServiceMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method
+func (m *MockmaintenanceService) List(params ...map[string]string) ([]pingdom.MaintenanceResponse, error) {
+	varargs := []interface{}{}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "List", varargs...)
+	ret0, _ := ret[0].([]pingdom.MaintenanceResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockmaintenanceServiceMockRecorder) List(params ...interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockmaintenanceService)(nil).List), params...)
+}
+
+// Create mocks base method
+func (m *MockmaintenanceService) Create(maintenance pingdom.Maintenance) (*pingdom.MaintenanceResponse, error) {
+	ret := m.ctrl.Call(m, "Create", maintenance)
+	ret0, _ := ret[0].(*pingdom.MaintenanceResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockmaintenanceServiceMockRecorder) Create(maintenance interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockmaintenanceService)(nil).Create), maintenance)
+}
+
+// Update mocks base method
+func (m *MockmaintenanceService) Update(id int, maintenance pingdom.Maintenance) (*pingdom.PingdomResponse, error) {
+	ret := m.ctrl.Call(m, "Update", id, maintenance)
+	ret0, _ := ret[0].(*pingdom.PingdomResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update
+func (mr *MockmaintenanceServiceMockRecorder) Update(id, maintenance interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockmaintenanceService)(nil).Update), id, maintenance)
+}
+
+// Delete mocks base method
+func (m *MockmaintenanceService) Delete(id int) (*pingdom.PingdomResponse, error) {
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(*pingdom.PingdomResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockmaintenanceServiceMockRecorder) Delete(id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockmaintenanceService)(nil).Delete), id)
+}
+
+// MocksummaryService is a mock of summaryService interface
+type MocksummaryService struct {
+	ctrl     *gomock.Controller
+	recorder *MocksummaryServiceMockRecorder
+}
+
+// MocksummaryServiceMockRecorder is the mock recorder for MocksummaryService
+type MocksummaryServiceMockRecorder struct {
+	mock *MocksummaryService
+}
+
+// NewMocksummaryService creates a new mock instance
+func NewMocksummaryService(ctrl *gomock.Controller) *MocksummaryService {
+	mock := &MocksummaryService{ctrl: ctrl}
+	mock.recorder = &MocksummaryServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MocksummaryService) EXPECT() *MocksummaryServiceMockRecorder {
+	return m.recorder
+}
+
+// Average mocks base method
+func (m *MocksummaryService) Average(checkID int, from, to time.Time) (*summaryStatus, error) {
+	ret := m.ctrl.Call(m, "Average", checkID, from, to)
+	ret0, _ := ret[0].(*summaryStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Average indicates an expected call of Average
+func (mr *MocksummaryServiceMockRecorder) Average(checkID, from, to interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Average", reflect.TypeOf((*MocksummaryService)(nil).Average), checkID, from, to)
+}
+
+// Outages mocks base method
+func (m *MocksummaryService) Outages(checkID int, from, to time.Time) ([]summaryState, error) {
+	ret := m.ctrl.Call(m, "Outages", checkID, from, to)
+	ret0, _ := ret[0].([]summaryState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Outages indicates an expected call of Outages
+func (mr *MocksummaryServiceMockRecorder) Outages(checkID, from, to interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Outages", reflect.TypeOf((*MocksummaryService)(nil).Outages), checkID, from, to)
+}
+
+// MockalertingService is a mock of alertingService interface
+type MockalertingService struct {
+	ctrl     *gomock.Controller
+	recorder *MockalertingServiceMockRecorder
+}
+
+// MockalertingServiceMockRecorder is the mock recorder for MockalertingService
+type MockalertingServiceMockRecorder struct {
+	mock *MockalertingService
+}
+
+// NewMockalertingService creates a new mock instance
+func NewMockalertingService(ctrl *gomock.Controller) *MockalertingService {
+	mock := &MockalertingService{ctrl: ctrl}
+	mock.recorder = &MockalertingServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockalertingService) EXPECT() *MockalertingServiceMockRecorder {
+	return m.recorder
+}
+
+// Contacts mocks base method
+func (m *MockalertingService) Contacts() ([]alertingContact, error) {
+	ret := m.ctrl.Call(m, "Contacts")
+	ret0, _ := ret[0].([]alertingContact)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Contacts indicates an expected call of Contacts
+func (mr *MockalertingServiceMockRecorder) Contacts() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Contacts", reflect.TypeOf((*MockalertingService)(nil).Contacts))
+}
+
+// Teams mocks base method
+func (m *MockalertingService) Teams() ([]alertingTeam, error) {
+	ret := m.ctrl.Call(m, "Teams")
+	ret0, _ := ret[0].([]alertingTeam)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Teams indicates an expected call of Teams
+func (mr *MockalertingServiceMockRecorder) Teams() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Teams", reflect.TypeOf((*MockalertingService)(nil).Teams))
+}
+
 // MockpingdomClient is a mock of pingdomClient interface
 type MockpingdomClient struct {
 	ctrl     *gomock.Controller
@@ -184,3 +503,208 @@ func (m *MockpingdomClient) Checks() checkService {
 func (mr *MockpingdomClientMockRecorder) Checks() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checks", reflect.TypeOf((*MockpingdomClient)(nil).Checks))
 }
+
+// Teams mocks base method
+func (m *MockpingdomClient) Teams() teamService {
+	ret := m.ctrl.Call(m, "Teams")
+	ret0, _ := ret[0].(teamService)
+	return ret0
+}
+
+// Teams indicates an expected call of Teams
+func (mr *MockpingdomClientMockRecorder) Teams() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Teams", reflect.TypeOf((*MockpingdomClient)(nil).Teams))
+}
+
+// Maintenances mocks base method
+func (m *MockpingdomClient) Maintenances() maintenanceService {
+	ret := m.ctrl.Call(m, "Maintenances")
+	ret0, _ := ret[0].(maintenanceService)
+	return ret0
+}
+
+// Maintenances indicates an expected call of Maintenances
+func (mr *MockpingdomClientMockRecorder) Maintenances() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Maintenances", reflect.TypeOf((*MockpingdomClient)(nil).Maintenances))
+}
+
+// Summaries mocks base method
+func (m *MockpingdomClient) Summaries() summaryService {
+	ret := m.ctrl.Call(m, "Summaries")
+	ret0, _ := ret[0].(summaryService)
+	return ret0
+}
+
+// Summaries indicates an expected call of Summaries
+func (mr *MockpingdomClientMockRecorder) Summaries() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Summaries", reflect.TypeOf((*MockpingdomClient)(nil).Summaries))
+}
+
+// Alerting mocks base method
+func (m *MockpingdomClient) Alerting() alertingService {
+	ret := m.ctrl.Call(m, "Alerting")
+	ret0, _ := ret[0].(alertingService)
+	return ret0
+}
+
+// Alerting indicates an expected call of Alerting
+func (mr *MockpingdomClientMockRecorder) Alerting() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Alerting", reflect.TypeOf((*MockpingdomClient)(nil).Alerting))
+}
+
+// MockcheckClient is a mock of checkClient interface
+type MockcheckClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockcheckClientMockRecorder
+}
+
+// MockcheckClientMockRecorder is the mock recorder for MockcheckClient
+type MockcheckClientMockRecorder struct {
+	mock *MockcheckClient
+}
+
+// NewMockcheckClient creates a new mock instance
+func NewMockcheckClient(ctrl *gomock.Controller) *MockcheckClient {
+	mock := &MockcheckClient{ctrl: ctrl}
+	mock.recorder = &MockcheckClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockcheckClient) EXPECT() *MockcheckClientMockRecorder {
+	return m.recorder
+}
+
+// UpdateHTTPCheck mocks base method
+func (m *MockcheckClient) UpdateHTTPCheck(check v1alpha1.HTTPCheck) error {
+	ret := m.ctrl.Call(m, "UpdateHTTPCheck", check)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHTTPCheck indicates an expected call of UpdateHTTPCheck
+func (mr *MockcheckClientMockRecorder) UpdateHTTPCheck(check interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHTTPCheck", reflect.TypeOf((*MockcheckClient)(nil).UpdateHTTPCheck), check)
+}
+
+// DeleteHTTPCheck mocks base method
+func (m *MockcheckClient) DeleteHTTPCheck(check v1alpha1.HTTPCheck) error {
+	ret := m.ctrl.Call(m, "DeleteHTTPCheck", check)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteHTTPCheck indicates an expected call of DeleteHTTPCheck
+func (mr *MockcheckClientMockRecorder) DeleteHTTPCheck(check interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHTTPCheck", reflect.TypeOf((*MockcheckClient)(nil).DeleteHTTPCheck), check)
+}
+
+// DeleteOrphanedHTTPChecks mocks base method
+func (m *MockcheckClient) DeleteOrphanedHTTPChecks(list func() ([]v1alpha1.HTTPCheck, error)) error {
+	ret := m.ctrl.Call(m, "DeleteOrphanedHTTPChecks", list)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrphanedHTTPChecks indicates an expected call of DeleteOrphanedHTTPChecks
+func (mr *MockcheckClientMockRecorder) DeleteOrphanedHTTPChecks(list interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrphanedHTTPChecks", reflect.TypeOf((*MockcheckClient)(nil).DeleteOrphanedHTTPChecks), list)
+}
+
+// UpdateContact mocks base method
+func (m *MockcheckClient) UpdateContact(contact v1alpha1.PingdomContact) error {
+	ret := m.ctrl.Call(m, "UpdateContact", contact)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateContact indicates an expected call of UpdateContact
+func (mr *MockcheckClientMockRecorder) UpdateContact(contact interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateContact", reflect.TypeOf((*MockcheckClient)(nil).UpdateContact), contact)
+}
+
+// DeleteContact mocks base method
+func (m *MockcheckClient) DeleteContact(contact v1alpha1.PingdomContact) error {
+	ret := m.ctrl.Call(m, "DeleteContact", contact)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteContact indicates an expected call of DeleteContact
+func (mr *MockcheckClientMockRecorder) DeleteContact(contact interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteContact", reflect.TypeOf((*MockcheckClient)(nil).DeleteContact), contact)
+}
+
+// UpdateTeam mocks base method
+func (m *MockcheckClient) UpdateTeam(team v1alpha1.PingdomTeam) error {
+	ret := m.ctrl.Call(m, "UpdateTeam", team)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTeam indicates an expected call of UpdateTeam
+func (mr *MockcheckClientMockRecorder) UpdateTeam(team interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTeam", reflect.TypeOf((*MockcheckClient)(nil).UpdateTeam), team)
+}
+
+// DeleteTeam mocks base method
+func (m *MockcheckClient) DeleteTeam(team v1alpha1.PingdomTeam) error {
+	ret := m.ctrl.Call(m, "DeleteTeam", team)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTeam indicates an expected call of DeleteTeam
+func (mr *MockcheckClientMockRecorder) DeleteTeam(team interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTeam", reflect.TypeOf((*MockcheckClient)(nil).DeleteTeam), team)
+}
+
+// UpdateMaintenanceWindow mocks base method
+func (m *MockcheckClient) UpdateMaintenanceWindow(window v1alpha1.MaintenanceWindow, checks []v1alpha1.HTTPCheck) error {
+	ret := m.ctrl.Call(m, "UpdateMaintenanceWindow", window, checks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMaintenanceWindow indicates an expected call of UpdateMaintenanceWindow
+func (mr *MockcheckClientMockRecorder) UpdateMaintenanceWindow(window, checks interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMaintenanceWindow", reflect.TypeOf((*MockcheckClient)(nil).UpdateMaintenanceWindow), window, checks)
+}
+
+// DeleteMaintenanceWindow mocks base method
+func (m *MockcheckClient) DeleteMaintenanceWindow(window v1alpha1.MaintenanceWindow) error {
+	ret := m.ctrl.Call(m, "DeleteMaintenanceWindow", window)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMaintenanceWindow indicates an expected call of DeleteMaintenanceWindow
+func (mr *MockcheckClientMockRecorder) DeleteMaintenanceWindow(window interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMaintenanceWindow", reflect.TypeOf((*MockcheckClient)(nil).DeleteMaintenanceWindow), window)
+}
+
+// CheckStates mocks base method
+func (m *MockcheckClient) CheckStates(checks []v1alpha1.HTTPCheck) (map[string]CheckState, error) {
+	ret := m.ctrl.Call(m, "CheckStates", checks)
+	ret0, _ := ret[0].(map[string]CheckState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckStates indicates an expected call of CheckStates
+func (mr *MockcheckClientMockRecorder) CheckStates(checks interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckStates", reflect.TypeOf((*MockcheckClient)(nil).CheckStates), checks)
+}
+
+// Uptimes mocks base method
+func (m *MockcheckClient) Uptimes(check v1alpha1.HTTPCheck, windows []time.Duration) ([]Uptime, error) {
+	ret := m.ctrl.Call(m, "Uptimes", check, windows)
+	ret0, _ := ret[0].([]Uptime)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Uptimes indicates an expected call of Uptimes
+func (mr *MockcheckClientMockRecorder) Uptimes(check, windows interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Uptimes", reflect.TypeOf((*MockcheckClient)(nil).Uptimes), check, windows)
+}
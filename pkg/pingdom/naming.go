@@ -0,0 +1,193 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"text/template"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/russellcardullo/go-pingdom/pingdom"
+)
+
+// ownerTagPrefix prefixes the tag identifying the HTTPCheck a Pingdom check belongs to. Unlike
+// the name of the check it doesn't depend on the name template, so changing the template renames
+// checks instead of recreating them.
+const ownerTagPrefix = "heimdallr-id-"
+
+// clusterTagPrefix prefixes the tag identifying the cluster which manages a check, so that
+// instances of heimdallr in different clusters can share a Pingdom account.
+const clusterTagPrefix = "heimdallr-cluster-"
+
+// ownerMarker marks the names of the Pingdom users and teams heimdallr manages, which can't be
+// tagged like checks. The cluster which manages them is added to the marker, if set.
+const ownerMarker = "heimdallr"
+
+// NameData is the data available to check name templates.
+type NameData struct {
+	Cluster   string
+	Namespace string
+	Name      string
+	Spec      v1alpha1.HTTPCheckSpec
+	Labels    map[string]string
+}
+
+// ParseNameTemplate parses a template used to name checks, such as
+// `{{.Namespace}}/{{.Name}} {{.Spec.Hostname}}`.
+func ParseNameTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse name template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// WithNameTemplate configures the template used to name checks which don't specify their own.
+// Checks are named `<namespace>/<name>` by default.
+func WithNameTemplate(tmpl *template.Template) Option {
+	return func(c *Client) {
+		c.nameTemplate = tmpl
+	}
+}
+
+// WithCluster configures the identifier of the cluster the client manages checks for. Only
+// checks tagged with the cluster are managed by the client, and it prefixes the default name of
+// checks.
+func WithCluster(cluster string) Option {
+	return func(c *Client) {
+		c.cluster = cluster
+	}
+}
+
+// checkName returns the name of the Pingdom check for the given check.
+func (c *Client) checkName(check v1alpha1.HTTPCheck) (string, error) {
+	tmpl := c.nameTemplate
+	if check.Spec.NameTemplate != "" {
+		var err error
+		if tmpl, err = ParseNameTemplate(check.Spec.NameTemplate); err != nil {
+			return "", err
+		}
+	}
+	if tmpl == nil {
+		if c.cluster != "" {
+			return c.cluster + "/" + getName(check), nil
+		}
+		return getName(check), nil
+	}
+
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, NameData{
+		Cluster:   c.cluster,
+		Namespace: namespace(check),
+		Name:      check.Name,
+		Spec:      check.Spec,
+		Labels:    check.Labels,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render name template: %v", err)
+	}
+
+	name := strings.TrimSpace(buf.String())
+	if name == "" {
+		return "", fmt.Errorf("name template rendered an empty name")
+	}
+	return name, nil
+}
+
+// ownerTag returns the tag identifying the Pingdom check of the given check.
+func (c *Client) ownerTag(check v1alpha1.HTTPCheck) string {
+	if c.cluster != "" {
+		return ownerTagFromName(c.cluster + "/" + getName(check))
+	}
+	return ownerTagFromName(getName(check))
+}
+
+// isOwned returns whether a Pingdom check is managed by the client, that is it's managed by
+// heimdallr and tagged with the client's cluster, or untagged if the client has no cluster.
+func (c *Client) isOwned(cr pingdom.CheckResponse) bool {
+	if !isManaged(cr) {
+		return false
+	}
+
+	var cluster string
+	for _, tag := range cr.Tags {
+		if strings.HasPrefix(tag.Name, clusterTagPrefix) {
+			cluster = strings.TrimPrefix(tag.Name, clusterTagPrefix)
+		}
+	}
+	return cluster == c.cluster
+}
+
+// ownedName returns the name in Pingdom of the contact or team with the given qualified name
+// managed by the client, such as `web/search [heimdallr]`, or `web/search [heimdallr:us-east-1]`
+// for a client with a cluster.
+func (c *Client) ownedName(name string) string {
+	return name + " " + c.marker()
+}
+
+func (c *Client) marker() string {
+	if c.cluster != "" {
+		return "[" + ownerMarker + ":" + c.cluster + "]"
+	}
+	return "[" + ownerMarker + "]"
+}
+
+func ownerTagFromName(name string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return fmt.Sprintf("%s%016x", ownerTagPrefix, h.Sum64())
+}
+
+// checkOwner returns the tag identifying the check a managed Pingdom check belongs to. Checks
+// created before ownership tags were introduced are identified by their name, which was always
+// `<namespace>/<name>`, until they're next updated.
+func checkOwner(cr pingdom.CheckResponse) string {
+	for _, tag := range cr.Tags {
+		if strings.HasPrefix(tag.Name, ownerTagPrefix) {
+			return tag.Name
+		}
+	}
+	return ownerTagFromName(cr.Name)
+}
+
+func namespace(check v1alpha1.HTTPCheck) string {
+	if check.Namespace == "" {
+		return "default"
+	}
+	return check.Namespace
+}
+
+func getName(check v1alpha1.HTTPCheck) string {
+	return qualifiedName(check.Namespace, check.Name)
+}
+
+// qualifiedName returns the given name prefixed with its namespace, so that resources of the same
+// name in different namespaces don't collide in Pingdom.
+func qualifiedName(ns, name string) string {
+	if ns == "" {
+		ns = "default"
+	}
+	return ns + "/" + name
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"testing"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/russellcardullo/go-pingdom/pingdom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckName(t *testing.T) {
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "web",
+			Labels:    map[string]string{"team": "payments"},
+		},
+		Spec: v1alpha1.HTTPCheckSpec{Hostname: "foo.io"},
+	}
+
+	var client Client
+	name, err := client.checkName(check)
+	require.NoError(t, err)
+	assert.Equal(t, "web/foo", name)
+
+	tmpl, err := ParseNameTemplate("{{.Labels.team}}: {{.Namespace}}/{{.Name}} {{.Spec.Hostname}}")
+	require.NoError(t, err)
+	WithNameTemplate(tmpl)(&client)
+
+	name, err = client.checkName(check)
+	require.NoError(t, err)
+	assert.Equal(t, "payments: web/foo foo.io", name)
+
+	check.Spec.NameTemplate = "{{.Spec.Hostname}}"
+	name, err = client.checkName(check)
+	require.NoError(t, err)
+	assert.Equal(t, "foo.io", name)
+}
+
+func TestCheckNameErrors(t *testing.T) {
+	var client Client
+	for _, text := range []string{"{{.Name", "{{.Labels.missing}}", "{{if false}}x{{end}}"} {
+		check := v1alpha1.HTTPCheck{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+			Spec:       v1alpha1.HTTPCheckSpec{NameTemplate: text},
+		}
+		_, err := client.checkName(check)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestCheckOwner(t *testing.T) {
+	var client Client
+	check := v1alpha1.HTTPCheck{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "web"}}
+
+	// Checks are identified by their owner tag, regardless of their name.
+	tagged := pingdom.CheckResponse{
+		Name: "renamed",
+		Tags: []pingdom.CheckResponseTag{{Name: heimdallrTag}, {Name: client.ownerTag(check)}},
+	}
+	assert.Equal(t, client.ownerTag(check), checkOwner(tagged))
+
+	// Checks created before owner tags were introduced are identified by their name.
+	legacy := pingdom.CheckResponse{
+		Name: "web/foo",
+		Tags: []pingdom.CheckResponseTag{{Name: heimdallrTag}},
+	}
+	assert.Equal(t, client.ownerTag(check), checkOwner(legacy))
+}
+
+func TestCluster(t *testing.T) {
+	check := v1alpha1.HTTPCheck{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "web"}}
+
+	var (
+		client Client
+		east   = Client{cluster: "east"}
+	)
+
+	// The cluster prefixes the default name and is part of the owner tag.
+	name, err := east.checkName(check)
+	require.NoError(t, err)
+	assert.Equal(t, "east/web/foo", name)
+	assert.NotEqual(t, client.ownerTag(check), east.ownerTag(check))
+	assert.Equal(t, heimdallrTag+","+east.ownerTag(check)+",heimdallr-cluster-east",
+		east.tags(east.ownerTag(check), check))
+
+	tmpl, err := ParseNameTemplate("{{.Cluster}}: {{.Name}}")
+	require.NoError(t, err)
+	WithNameTemplate(tmpl)(&east)
+	name, err = east.checkName(check)
+	require.NoError(t, err)
+	assert.Equal(t, "east: foo", name)
+
+	// Clients only own the checks tagged with their cluster.
+	var (
+		untagged = pingdom.CheckResponse{Tags: []pingdom.CheckResponseTag{{Name: heimdallrTag}}}
+		tagged   = pingdom.CheckResponse{Tags: []pingdom.CheckResponseTag{
+			{Name: heimdallrTag}, {Name: "heimdallr-cluster-east"},
+		}}
+		unmanaged = pingdom.CheckResponse{Tags: []pingdom.CheckResponseTag{{Name: "heimdallr-cluster-east"}}}
+	)
+	assert.True(t, client.isOwned(untagged))
+	assert.False(t, client.isOwned(tagged))
+	assert.False(t, east.isOwned(untagged))
+	assert.True(t, east.isOwned(tagged))
+	assert.False(t, east.isOwned(unmanaged))
+}
@@ -23,6 +23,9 @@ package pingdom
 
 import (
 	"fmt"
+	"net/http"
+	"sync"
+	"text/template"
 
 	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
 
@@ -37,74 +40,209 @@ type httpCheck struct {
 	id   int
 	name string
 	spec v1alpha1.HTTPCheckSpec
+
+	// rcpts are the users and teams the check was last updated to alert. They're nil if the check
+	// was read from Pingdom, which doesn't return them, so the next update isn't skipped.
+	rcpts *recipients
+
+	// listed is set if the check is only known from the list of checks, so its spec only has the
+	// fields the list includes.
+	listed bool
 }
 
 // Client is a Pingdom API Client.
 type Client struct {
-	userID     int
-	client     pingdomClient
+	cluster      string
+	contactIDs   []int
+	labelPrefix  string
+	labelKeys    map[string]bool
+	integrations map[string]int
+	directory    directory
+	uptimes      uptimeCache
+	defaults     v1alpha1.HTTPCheckSpec
+	nameTemplate *template.Template
+	onRecreate   func(v1alpha1.HTTPCheck)
+	client       pingdomClient
+	transport    *rateLimitTransport
+	dryRun       bool
+	logger       *zap.Logger
+
+	// httpChecks are the checks managed by heimdallr keyed by their owner tag. mu guards them so
+	// that different checks can be updated concurrently.
+	mu         sync.RWMutex
 	httpChecks map[string]httpCheck
-	logger     *zap.Logger
 }
 
-// New creates a new Pingdom client.
-func New(user, password, key string, logger *zap.Logger) (*Client, error) {
-	var (
-		client = pingdom.NewClient(user, password, key)
-		shim   = newShimClient(client)
-	)
-	return new(user, shim, logger)
+// Option configures a Client.
+type Option func(*Client)
+
+// WithDryRun configures whether the client only logs the changes it would make to checks
+// instead of making them. The current state of Pingdom is still read.
+func WithDryRun(dryRun bool) Option {
+	return func(c *Client) {
+		c.dryRun = dryRun
+	}
 }
 
-func new(user string, client pingdomClient, logger *zap.Logger) (*Client, error) {
-	users, err := client.Users().List()
+// WithContactIDs configures the IDs of the users alerted by checks. By default the user the
+// client authenticates as is alerted, if it can be determined.
+func WithContactIDs(ids ...int) Option {
+	return func(c *Client) {
+		c.contactIDs = ids
+	}
+}
+
+// WithDefaults configures the interval, thresholds and integrations of checks which don't
+// specify them.
+func WithDefaults(defaults v1alpha1.HTTPCheckSpec) Option {
+	return func(c *Client) {
+		c.defaults = defaults
+	}
+}
+
+// WithRecreateHandler configures a function which is called with a check after it was recreated
+// because it had been deleted from Pingdom outside of heimdallr.
+func WithRecreateHandler(fn func(v1alpha1.HTTPCheck)) Option {
+	return func(c *Client) {
+		c.onRecreate = fn
+	}
+}
+
+// Credentials are the credentials of a Pingdom account. Either a Token or a Username, Password
+// and AppKey are required.
+type Credentials struct {
+	Username string
+	Password string
+	AppKey   string
+	Token    string
+}
+
+// NewFromCredentials creates a new Pingdom client for the account with the given credentials.
+func NewFromCredentials(creds Credentials, logger *zap.Logger, opts ...Option) (*Client, error) {
+	if creds.Token != "" {
+		return NewWithToken(creds.Token, logger, opts...)
+	}
+	return New(creds.Username, creds.Password, creds.AppKey, logger, opts...)
+}
+
+// NewWithToken creates a new Pingdom client which authenticates with a 3.1 API token.
+func NewWithToken(token string, logger *zap.Logger, opts ...Option) (*Client, error) {
+	return newWithToken(apiBaseURL, token, logger, opts...)
+}
+
+func newWithToken(baseURL, token string, logger *zap.Logger, opts ...Option) (*Client, error) {
+	transport := sharedRateLimitTransport("token:"+token, func() http.RoundTripper {
+		return newBearerTransport(token, http.DefaultTransport)
+	})
+	client, err := pingdom.NewClientWithConfig(pingdom.ClientConfig{
+		BaseURL:    baseURL,
+		HTTPClient: &http.Client{Transport: transport},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get list of users for account: %v", err)
+		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
+	opts = append([]Option{withAlertingAPI()}, opts...)
+	return new("", newShimClient(client), logger, withTransport(transport, opts)...)
+}
 
-	var userID *int
-	for _, userResp := range users {
-		for _, email := range userResp.Email {
-			if email.Address == user {
-				userID = &userResp.Id
-				break
-			}
-		}
+// withAlertingAPI configures the client to look up contacts and teams with the alerting API of
+// version 3.1, since the users and teams of version 2.1 aren't available to token authenticated
+// clients.
+func withAlertingAPI() Option {
+	return func(c *Client) {
+		c.directory.alerting = true
+	}
+}
+
+// New creates a new Pingdom client.
+func New(user, password, key string, logger *zap.Logger, opts ...Option) (*Client, error) {
+	transport := sharedRateLimitTransport("user:"+user+":"+key, func() http.RoundTripper {
+		return http.DefaultTransport
+	})
+	client, err := pingdom.NewClientWithConfig(pingdom.ClientConfig{
+		User:       user,
+		Password:   password,
+		APIKey:     key,
+		BaseURL:    legacyBaseURL,
+		HTTPClient: &http.Client{Transport: transport},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
+	return new(user, newShimClient(client), logger, withTransport(transport, opts)...)
+}
 
-	if userID == nil {
-		return nil, fmt.Errorf("failed to get ID of user %v", user)
+// withTransport prepends an option setting the transport requests to Pingdom are rate limited
+// by, so that the options given can configure it.
+func withTransport(transport *rateLimitTransport, opts []Option) []Option {
+	set := func(c *Client) {
+		c.transport = transport
 	}
+	return append([]Option{set}, opts...)
+}
 
+// new creates a new client. The ID of the given user is looked up to alert them if no contacts
+// are configured. Token authenticated clients don't have a user, so no one is alerted unless
+// contacts are configured.
+func new(user string, client pingdomClient, logger *zap.Logger, opts ...Option) (*Client, error) {
 	c := &Client{
-		userID:     *userID,
 		client:     client,
 		httpChecks: make(map[string]httpCheck),
 		logger:     logger,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if len(c.contactIDs) == 0 {
+		if user == "" {
+			logger.Warn("no contacts configured, checks will not alert any users")
+		} else {
+			userID, err := lookupUserID(client, user)
+			if err != nil {
+				return nil, err
+			}
+			c.contactIDs = []int{userID}
+		}
+	}
 
 	return c, c.sync()
 }
 
+func lookupUserID(client pingdomClient, user string) (int, error) {
+	users, err := client.Users().List()
+	if err != nil {
+		return 0, classify("get list of users for account", err)
+	}
+
+	for _, userResp := range users {
+		for _, email := range userResp.Email {
+			if email.Address == user {
+				return userResp.Id, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("failed to get ID of user %v", user)
+}
+
 // Sync fetches the current state of Pingdom.
 func (c *Client) sync() error {
-	list, err := c.client.Checks().List(map[string]string{
+	list, err := c.listChecks(map[string]string{
 		"tags":         heimdallrTag,
 		"include_tags": "true",
 	})
 	if err != nil {
-		return fmt.Errorf("failed to get current list of heimdallr checks: %v", err)
+		return classify("get current list of heimdallr checks", err)
 	}
 	c.logger.Info("found existing checks, checking if any are managed by heimdallr", zap.Int("count", len(list)))
 
+	// The list includes the tags of each check, so the checks we own are found without reading
+	// them. Their details aren't read either, since checks whose recipients are unknown are
+	// updated the first time they're synced regardless of their other fields.
 	for _, cr := range list {
-		check, ok, err := c.toHTTPCheck(cr)
-		if err != nil {
-			return fmt.Errorf("failed to get information for check %v: %v", cr.Name, err)
-		}
-
-		if ok {
-			c.httpChecks[cr.Name] = check
+		if c.isOwned(cr) {
+			c.httpChecks[checkOwner(cr)] = listedHTTPCheck(cr)
 			c.logger.Info("found pre-existing check", zap.String("name", cr.Name))
 		}
 	}
@@ -113,108 +251,363 @@ func (c *Client) sync() error {
 
 // UpdateHTTPCheck updates an HTTP check, creating it if it does not exist.
 func (c *Client) UpdateHTTPCheck(check v1alpha1.HTTPCheck) error {
-	name := getName(check)
-
-	pc := pingdom.HttpCheck{
-		Name:                     name,
-		UserIds:                  []int{c.userID},
-		Hostname:                 check.Spec.Hostname,
-		Resolution:               check.Spec.IntervalMinutes,
-		Encryption:               check.Spec.EnableTLS,
-		SendNotificationWhenDown: check.Spec.TriggerThreshold,
-		NotifyAgainEvery:         check.Spec.RetriggerThreshold,
-		NotifyWhenBackup:         check.Spec.NotifyWhenBackup,
-		Tags:                     heimdallrTag,
-		IntegrationIds:           check.Spec.IntegrationIDs,
-	}
-
-	hc, ok := c.httpChecks[name]
+	key := c.ownerTag(check)
+	name, err := c.checkName(check)
+	if err != nil {
+		return &ValidationError{Op: "name check", Field: "nameTemplate", Message: err.Error()}
+	}
+	spec, rcpts, err := c.resolve(check.Namespace, check.Spec)
+	if err != nil {
+		return err
+	}
+	check.Spec = c.withDefaults(spec)
+	check.Spec.Tags = c.userTags(check)
+
+	pc, err := c.pingdomCheck(key, name, check, rcpts)
+	if err != nil {
+		return err
+	}
+
+	hc, ok := c.lookup(key)
+	if ok && checkType(hc.spec) != checkType(check.Spec) {
+		// Pingdom can't change the type of a check, so the check is replaced instead.
+		c.logger.Info("replacing check whose type changed", zap.String("name", hc.name))
+		if err := c.deleteHTTPCheck(key); err != nil {
+			return err
+		}
+		ok = false
+	}
 	if ok {
-		_, err := c.client.Checks().Update(hc.id, &pc)
-		if err != nil {
-			return fmt.Errorf("failed to update check: %v", err)
+		diffs := diffCheck(hc, name, check.Spec, rcpts)
+		if len(diffs) == 0 {
+			c.logger.Debug("check is up to date", zap.String("name", name))
+			return nil
 		}
+
+		if c.dryRun {
+			c.logger.Info(
+				"dry run: would update check",
+				zap.String("name", hc.name),
+				zap.String("newName", name),
+				zap.Any("diff", diffs),
+			)
+		} else {
+			_, err := c.client.Checks().Update(hc.id, pc)
+			switch err := classify("update check", err); {
+			case IsNotFound(err):
+				// The cached ID is stale because the check was deleted outside of heimdallr.
+				c.logger.Warn("check was deleted from pingdom, recreating it", zap.String("name", hc.name), zap.Int("id", hc.id))
+				c.forget(key)
+				c.uptimes.forget(hc.id)
+
+				res, err := c.client.Checks().Create(pc)
+				if err != nil {
+					return classify("recreate check", err)
+				}
+				c.store(key, httpCheck{id: res.ID, name: name, spec: check.Spec, rcpts: &rcpts})
+				c.logger.Info("successfully recreated check", zap.String("name", name), zap.Int("id", res.ID))
+				recordRecreated()
+				recordOperation(ActionCreate, c.dryRun)
+				if c.onRecreate != nil {
+					c.onRecreate(check)
+				}
+				return nil
+			case err != nil:
+				return err
+			}
+			c.logger.Info("successfully updated check", zap.String("name", name), zap.Any("diff", diffs))
+		}
+		hc.name = name
 		hc.spec = check.Spec
-		c.logger.Info("successfully updated check", zap.String("name", hc.name))
+		hc.rcpts = &rcpts
+		hc.listed = false
+		recordOperation(ActionUpdate, c.dryRun)
 	} else {
-		res, err := c.client.Checks().Create(&pc)
-		if err != nil {
-			return fmt.Errorf("failed to create check: %v", err)
+		// In dry run mode the check is still cached, without an ID, so that later operations on
+		// it are reported as they would be if it had been created.
+		var id int
+		if c.dryRun {
+			c.logger.Info("dry run: would create check", zap.String("name", name), zap.Any("check", pc))
+		} else {
+			res, err := c.client.Checks().Create(pc)
+			if err != nil {
+				return classify("create check", err)
+			}
+			id = res.ID
+			c.logger.Info("successfully created check", zap.String("name", name))
 		}
 		hc = httpCheck{
-			id:   res.ID,
-			name: name,
-			spec: check.Spec,
+			id:    id,
+			name:  name,
+			spec:  check.Spec,
+			rcpts: &rcpts,
 		}
-		c.logger.Info("successfully created check", zap.String("name", hc.name))
+		recordOperation(ActionCreate, c.dryRun)
 	}
 
-	c.httpChecks[name] = hc
+	c.store(key, hc)
 	return nil
 }
 
 // DeleteHTTPCheck deletes an HTTP check.
 func (c *Client) DeleteHTTPCheck(check v1alpha1.HTTPCheck) error {
-	name := getName(check)
-	hc, exists := c.httpChecks[name]
-	if !exists {
+	return c.deleteHTTPCheck(c.ownerTag(check))
+}
+
+// DeleteOrphanedHTTPChecks deletes the checks managed by the client which don't belong to any of
+// the checks returned by list, such as the checks of HTTPChecks deleted while heimdallr wasn't
+// running. The managed checks are read before calling list, so that checks created since the
+// HTTPChecks were listed aren't mistaken for orphans.
+func (c *Client) DeleteOrphanedHTTPChecks(list func() ([]v1alpha1.HTTPCheck, error)) error {
+	c.mu.RLock()
+	owned := make(map[string]string, len(c.httpChecks))
+	for key, hc := range c.httpChecks {
+		owned[key] = hc.name
+	}
+	c.mu.RUnlock()
+	if len(owned) == 0 {
 		return nil
 	}
 
-	_, err := c.client.Checks().Delete(hc.id)
+	checks, err := list()
 	if err != nil {
-		return fmt.Errorf("failed to delete check: %v", err)
+		return fmt.Errorf("failed to list checks: %v", err)
+	}
+	for _, check := range checks {
+		delete(owned, c.ownerTag(check))
 	}
 
-	delete(c.httpChecks, name)
-	c.logger.Info("successfully deleted check", zap.String("name", name))
+	for key, name := range owned {
+		c.logger.Info("deleting check which no longer exists", zap.String("name", name))
+		if err := c.deleteHTTPCheck(key); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (c *Client) toHTTPCheck(cr pingdom.CheckResponse) (httpCheck, bool, error) {
-	var found bool
-	for _, tag := range cr.Tags {
-		if tag.Name == heimdallrTag {
-			found = true
-			break
+func (c *Client) deleteHTTPCheck(key string) error {
+	hc, exists := c.lookup(key)
+	if !exists {
+		return nil
+	}
+
+	if c.dryRun {
+		c.logger.Info("dry run: would delete check", zap.String("name", hc.name), zap.Int("id", hc.id))
+	} else {
+		_, err := c.client.Checks().Delete(hc.id)
+		switch err := classify("delete check", err); {
+		case IsNotFound(err):
+			// The check was already deleted outside of heimdallr.
+			c.logger.Info("check was already deleted", zap.String("name", hc.name))
+		case err != nil:
+			return err
+		default:
+			c.logger.Info("successfully deleted check", zap.String("name", hc.name))
 		}
 	}
 
-	if !found {
-		// This check isn't managed by us.
-		return httpCheck{}, false, nil
+	c.forget(key)
+	c.uptimes.forget(hc.id)
+	recordOperation(ActionDelete, c.dryRun)
+	return nil
+}
+
+// UnmanagedHTTPCheck is an HTTP check in Pingdom which isn't managed by heimdallr.
+type UnmanagedHTTPCheck struct {
+	ID   int
+	Name string
+	Spec v1alpha1.HTTPCheckSpec
+}
+
+// ListUnmanagedHTTPChecks returns the HTTP checks in Pingdom which aren't managed by heimdallr.
+func (c *Client) ListUnmanagedHTTPChecks() ([]UnmanagedHTTPCheck, error) {
+	list, err := c.listChecks(map[string]string{
+		"include_tags": "true",
+	})
+	if err != nil {
+		return nil, classify("get current list of checks", err)
+	}
+
+	var unmanaged []pingdom.CheckResponse
+	for _, cr := range list {
+		if !isManaged(cr) && cr.Type.Name == "http" {
+			unmanaged = append(unmanaged, cr)
+		}
 	}
 
-	chk, err := c.client.Checks().Read(cr.ID)
+	read, err := c.readHTTPChecks(unmanaged)
 	if err != nil {
-		return httpCheck{}, false, err
+		return nil, err
 	}
 
-	var tlsEnabled bool
-	if chk.Type.HTTP != nil {
+	var checks []UnmanagedHTTPCheck
+	for _, check := range read {
+		checks = append(checks, UnmanagedHTTPCheck{
+			ID:   check.id,
+			Name: check.name,
+			Spec: check.spec,
+		})
+	}
+	return checks, nil
+}
+
+// AdoptHTTPCheck brings an existing Pingdom check under the management of heimdallr by
+// updating it to match the given check.
+func (c *Client) AdoptHTTPCheck(id int, check v1alpha1.HTTPCheck) error {
+	key := c.ownerTag(check)
+	if _, ok := c.lookup(key); ok {
+		return fmt.Errorf("check %v is already managed by heimdallr", getName(check))
+	}
+
+	c.store(key, httpCheck{id: id})
+	if err := c.UpdateHTTPCheck(check); err != nil {
+		c.forget(key)
+		return err
+	}
+	return nil
+}
+
+// lookup returns the cached check with the given owner tag.
+func (c *Client) lookup(key string) (httpCheck, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	hc, ok := c.httpChecks[key]
+	return hc, ok
+}
+
+func (c *Client) store(key string, hc httpCheck) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.httpChecks[key] = hc
+}
+
+func (c *Client) forget(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.httpChecks, key)
+}
+
+func (c *Client) readHTTPCheck(id int) (httpCheck, error) {
+	chk, err := c.client.Checks().Read(id)
+	if err != nil {
+		return httpCheck{}, classify(fmt.Sprintf("read check %v", id), err)
+	}
+
+	var (
+		typ        v1alpha1.CheckType
+		url        string
+		port       int
+		tlsEnabled bool
+	)
+	switch {
+	case chk.Type.HTTP != nil:
+		url = chk.Type.HTTP.Url
+		port = chk.Type.HTTP.Port
 		tlsEnabled = chk.Type.HTTP.Encryption
+	case chk.Type.TCP != nil:
+		typ = v1alpha1.CheckTypeTCP
+		port = chk.Type.TCP.Port
 	}
 
 	return httpCheck{
-		id:   cr.ID,
-		name: cr.Name,
+		id:   id,
+		name: chk.Name,
 		spec: v1alpha1.HTTPCheckSpec{
+			Type:               typ,
 			Hostname:           chk.Hostname,
+			URL:                url,
+			Port:               port,
 			IntervalMinutes:    chk.Resolution,
 			TriggerThreshold:   chk.SendNotificationWhenDown,
 			RetriggerThreshold: chk.NotifyAgainEvery,
 			NotifyWhenBackup:   chk.NotifyWhenBackup,
 			EnableTLS:          tlsEnabled,
 			IntegrationIDs:     chk.IntegrationIds,
+			Tags:               readUserTags(chk),
+			Paused:             chk.Paused,
 		},
-	}, true, nil
+	}, nil
 }
 
-func getName(check v1alpha1.HTTPCheck) string {
-	ns := check.ObjectMeta.Namespace
-	if ns == "" {
-		ns = "default"
+func isManaged(cr pingdom.CheckResponse) bool {
+	for _, tag := range cr.Tags {
+		if tag.Name == heimdallrTag {
+			return true
+		}
 	}
+	return false
+}
 
-	return fmt.Sprintf("%s/%s", ns, check.ObjectMeta.Name)
+// pingdomCheck returns the Pingdom check for the given check, named name and tagged with the
+// given owner tag, of the check's type.
+func (c *Client) pingdomCheck(key, name string, check v1alpha1.HTTPCheck, rcpts recipients) (pingdom.Check, error) {
+	switch checkType(check.Spec) {
+	case v1alpha1.CheckTypeHTTP:
+		return &pingdom.HttpCheck{
+			Name:                     name,
+			UserIds:                  rcpts.userIDs,
+			TeamIds:                  rcpts.teamIDs,
+			Hostname:                 check.Spec.Hostname,
+			Url:                      check.Spec.URL,
+			Port:                     check.Spec.Port,
+			Resolution:               check.Spec.IntervalMinutes,
+			Encryption:               check.Spec.EnableTLS,
+			SendNotificationWhenDown: check.Spec.TriggerThreshold,
+			NotifyAgainEvery:         check.Spec.RetriggerThreshold,
+			NotifyWhenBackup:         check.Spec.NotifyWhenBackup,
+			Paused:                   check.Spec.Paused,
+			Tags:                     c.tags(key, check),
+			IntegrationIds:           check.Spec.IntegrationIDs,
+		}, nil
+	case v1alpha1.CheckTypeTCP:
+		if check.Spec.Port == 0 {
+			return nil, &ValidationError{Op: "create check", Field: "port", Message: "tcp checks require a port"}
+		}
+		return &tcpCheck{
+			Name:                     name,
+			UserIds:                  rcpts.userIDs,
+			TeamIds:                  rcpts.teamIDs,
+			Hostname:                 check.Spec.Hostname,
+			Port:                     check.Spec.Port,
+			Resolution:               check.Spec.IntervalMinutes,
+			SendNotificationWhenDown: check.Spec.TriggerThreshold,
+			NotifyAgainEvery:         check.Spec.RetriggerThreshold,
+			NotifyWhenBackup:         check.Spec.NotifyWhenBackup,
+			Paused:                   check.Spec.Paused,
+			Tags:                     c.tags(key, check),
+			IntegrationIds:           check.Spec.IntegrationIDs,
+		}, nil
+	default:
+		return nil, &ValidationError{
+			Op:      "create check",
+			Field:   "type",
+			Message: fmt.Sprintf("unknown check type %q, must be http or tcp", check.Spec.Type),
+		}
+	}
+}
+
+// checkType returns the type of the check with the given spec, which is http if it's unset.
+func checkType(spec v1alpha1.HTTPCheckSpec) v1alpha1.CheckType {
+	if spec.Type == "" {
+		return v1alpha1.CheckTypeHTTP
+	}
+	return spec.Type
+}
+
+// withDefaults returns the spec with any unset settings replaced by the client's defaults.
+func (c *Client) withDefaults(spec v1alpha1.HTTPCheckSpec) v1alpha1.HTTPCheckSpec {
+	if spec.IntervalMinutes == 0 {
+		spec.IntervalMinutes = c.defaults.IntervalMinutes
+	}
+	if spec.TriggerThreshold == 0 {
+		spec.TriggerThreshold = c.defaults.TriggerThreshold
+	}
+	if spec.RetriggerThreshold == 0 {
+		spec.RetriggerThreshold = c.defaults.RetriggerThreshold
+	}
+	if len(spec.IntegrationIDs) == 0 {
+		spec.IntegrationIDs = c.defaults.IntegrationIDs
+	}
+	return spec
 }
@@ -21,11 +21,13 @@
 package pingdom
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
 
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/russellcardullo/go-pingdom/pingdom"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -69,23 +71,39 @@ func TestNewClient(t *testing.T) {
 		}, nil)
 
 	checks.EXPECT().
-		List(map[string]string{"tags": heimdallrTag, "include_tags": "true"}).
+		List(map[string]string{"tags": heimdallrTag, "include_tags": "true", "limit": "25000", "offset": "0"}).
 		Return([]pingdom.CheckResponse{*response}, nil)
 
-	checks.EXPECT().
-		Read(checkID).
-		Return(response, nil)
-
 	cli.EXPECT().Users().Return(users)
-	cli.EXPECT().Checks().Times(2).Return(checks)
+	cli.EXPECT().Checks().Return(checks)
 
 	client, err := new(user, cli, zap.NewNop())
 	require.NoError(t, err)
-	assert.Equal(t, userID, client.userID)
+	assert.Equal(t, []int{userID}, client.contactIDs)
 
 	assert.Len(t, client.httpChecks, 1)
 }
 
+func TestNewClientWithContactIDs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+	)
+
+	// Users are not looked up when contacts are configured.
+	checks.EXPECT().
+		List(map[string]string{"tags": heimdallrTag, "include_tags": "true", "limit": "25000", "offset": "0"}).
+		Return(nil, nil)
+	cli.EXPECT().Checks().Return(checks)
+
+	client, err := new("bob@example.com", cli, zap.NewNop(), WithContactIDs(1, 2))
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, client.contactIDs)
+}
+
 func TestSync(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -124,6 +142,7 @@ func TestSync(t *testing.T) {
 			SendNotificationWhenDown: 2,
 			NotifyAgainEvery:         8,
 			NotifyWhenBackup:         false,
+			Status:                   "paused",
 			Tags: []pingdom.CheckResponseTag{
 				{
 					Name: heimdallrTag,
@@ -138,23 +157,14 @@ func TestSync(t *testing.T) {
 	)
 
 	checks.EXPECT().
-		List(map[string]string{"tags": heimdallrTag, "include_tags": "true"}).
+		List(map[string]string{"tags": heimdallrTag, "include_tags": "true", "limit": "25000", "offset": "0"}).
 		Return([]pingdom.CheckResponse{
 			*firstCheck,
 			*secondCheck,
 		}, nil)
 
-	checks.
-		EXPECT().
-		Read(firstCheckID).
-		Return(firstCheck, nil)
-
-	checks.
-		EXPECT().
-		Read(secondCheckID).
-		Return(secondCheck, nil)
-
-	cli.EXPECT().Checks().Times(3).Return(checks)
+	// The checks aren't read, only the fields the list includes are known.
+	cli.EXPECT().Checks().Return(checks)
 
 	client := Client{
 		client:     cli,
@@ -169,30 +179,24 @@ func TestSync(t *testing.T) {
 		id:   71,
 		name: "default/foo",
 		spec: v1alpha1.HTTPCheckSpec{
-			Hostname:           "foo.io",
-			IntervalMinutes:    10,
-			TriggerThreshold:   3,
-			RetriggerThreshold: 30,
-			NotifyWhenBackup:   true,
-			EnableTLS:          false,
-			IntegrationIDs:     []int{7},
+			Hostname:        "foo.io",
+			IntervalMinutes: 10,
 		},
+		listed: true,
 	}
-	assert.Equal(t, expected, client.httpChecks["default/foo"])
+	assert.Equal(t, expected, client.httpChecks[ownerTagFromName("default/foo")])
 
 	expected = httpCheck{
 		id:   82,
 		name: "other/bar",
 		spec: v1alpha1.HTTPCheckSpec{
-			Hostname:           "bar.com",
-			IntervalMinutes:    5,
-			TriggerThreshold:   2,
-			RetriggerThreshold: 8,
-			NotifyWhenBackup:   false,
-			EnableTLS:          true,
+			Hostname:        "bar.com",
+			IntervalMinutes: 5,
+			Paused:          true,
 		},
+		listed: true,
 	}
-	assert.Equal(t, expected, client.httpChecks["other/bar"])
+	assert.Equal(t, expected, client.httpChecks[ownerTagFromName("other/bar")])
 }
 
 func TestUpdateHTTPCheckWithNewCheck(t *testing.T) {
@@ -240,11 +244,56 @@ func TestUpdateHTTPCheckWithNewCheck(t *testing.T) {
 	assert.Len(t, client.httpChecks, 1)
 
 	expected := httpCheck{
-		id:   id,
-		name: name,
-		spec: spec,
+		id:    id,
+		name:  name,
+		spec:  spec,
+		rcpts: &recipients{},
+	}
+	assert.Equal(t, expected, client.httpChecks[ownerTagFromName(name)])
+}
+
+func TestUpdateHTTPCheckWithDefaults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		check = v1alpha1.HTTPCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "other",
+			},
+			Spec: v1alpha1.HTTPCheckSpec{
+				Hostname:         "foo.io",
+				TriggerThreshold: 3,
+			},
+		}
+
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+	)
+
+	checks.EXPECT().Create(gomock.Any()).Do(func(pc pingdom.Check) {
+		hc := pc.(*pingdom.HttpCheck)
+		assert.Equal(t, 1, hc.Resolution)
+		assert.Equal(t, 3, hc.SendNotificationWhenDown)
+		assert.Equal(t, []int{7}, hc.IntegrationIds)
+	}).Return(&pingdom.CheckResponse{ID: 42}, nil)
+
+	cli.EXPECT().Checks().Return(checks)
+
+	client := Client{
+		client:     cli,
+		httpChecks: map[string]httpCheck{},
+		logger:     zap.NewNop(),
 	}
-	assert.Equal(t, expected, client.httpChecks[name])
+	WithDefaults(v1alpha1.HTTPCheckSpec{
+		IntervalMinutes:  1,
+		TriggerThreshold: 2,
+		IntegrationIDs:   []int{7},
+	})(&client)
+
+	require.NoError(t, client.UpdateHTTPCheck(check))
+	assert.Equal(t, 1, client.httpChecks[ownerTagFromName("other/foo")].spec.IntervalMinutes)
 }
 
 func TestUpdateHTTPCheckWithExistingCheck(t *testing.T) {
@@ -280,7 +329,7 @@ func TestUpdateHTTPCheckWithExistingCheck(t *testing.T) {
 	client := Client{
 		client: cli,
 		httpChecks: map[string]httpCheck{
-			name: {
+			ownerTagFromName(name): {
 				id:   id,
 				name: name,
 			},
@@ -293,11 +342,296 @@ func TestUpdateHTTPCheckWithExistingCheck(t *testing.T) {
 	assert.Len(t, client.httpChecks, 1)
 
 	expected := httpCheck{
-		id:   id,
-		name: name,
-		spec: spec,
+		id:    id,
+		name:  name,
+		spec:  spec,
+		rcpts: &recipients{},
+	}
+	assert.Equal(t, expected, client.httpChecks[ownerTagFromName(name)])
+}
+
+func TestUpdateHTTPCheckTCP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		check = v1alpha1.HTTPCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "postgres",
+				Namespace: "other",
+			},
+			Spec: v1alpha1.HTTPCheckSpec{
+				Type:     v1alpha1.CheckTypeTCP,
+				Hostname: "10.0.0.1",
+				Port:     5432,
+			},
+		}
+
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+	)
+
+	checks.EXPECT().Create(gomock.Any()).Do(func(pc pingdom.Check) {
+		tc, ok := pc.(*tcpCheck)
+		require.True(t, ok, "expected a tcp check, got %T", pc)
+		assert.Equal(t, "10.0.0.1", tc.Hostname)
+		assert.Equal(t, 5432, tc.Port)
+	}).Return(&pingdom.CheckResponse{ID: 42}, nil)
+	cli.EXPECT().Checks().Return(checks)
+
+	client := Client{
+		client:     cli,
+		httpChecks: map[string]httpCheck{},
+		logger:     zap.NewNop(),
+	}
+	require.NoError(t, client.UpdateHTTPCheck(check))
+
+	// A TCP check without a port is invalid.
+	check.Spec.Port = 0
+	err := client.UpdateHTTPCheck(check)
+	assert.Equal(t, &ValidationError{Op: "create check", Field: "port", Message: "tcp checks require a port"}, err)
+}
+
+func TestUpdateHTTPCheckTypeChanged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		name  = "other/foo"
+		check = v1alpha1.HTTPCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "other",
+			},
+			Spec: v1alpha1.HTTPCheckSpec{
+				Type:     v1alpha1.CheckTypeTCP,
+				Hostname: "foo.io",
+				Port:     5432,
+			},
+		}
+
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+	)
+
+	// Pingdom can't change the type of a check, so the HTTP check is replaced by a TCP one.
+	gomock.InOrder(
+		checks.EXPECT().Delete(42),
+		checks.EXPECT().Create(gomock.Any()).Return(&pingdom.CheckResponse{ID: 43}, nil),
+	)
+	cli.EXPECT().Checks().AnyTimes().Return(checks)
+
+	client := Client{
+		client: cli,
+		httpChecks: map[string]httpCheck{
+			ownerTagFromName(name): {
+				id:   42,
+				name: name,
+				spec: v1alpha1.HTTPCheckSpec{Hostname: "foo.io", Port: 5432},
+			},
+		},
+		logger: zap.NewNop(),
+	}
+
+	require.NoError(t, client.UpdateHTTPCheck(check))
+	assert.Equal(t, 43, client.httpChecks[ownerTagFromName(name)].id)
+}
+
+func TestUpdateHTTPCheckPaused(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		check = v1alpha1.HTTPCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "other",
+			},
+			Spec: v1alpha1.HTTPCheckSpec{
+				Hostname: "foo.io",
+				Paused:   true,
+			},
+		}
+
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+	)
+
+	checks.EXPECT().Update(42, gomock.Any()).Do(func(_ int, pc pingdom.Check) {
+		assert.True(t, pc.(*pingdom.HttpCheck).Paused)
+	})
+	cli.EXPECT().Checks().Return(checks)
+
+	client := Client{
+		client: cli,
+		httpChecks: map[string]httpCheck{
+			ownerTagFromName("other/foo"): {id: 42, name: "other/foo"},
+		},
+		logger: zap.NewNop(),
+	}
+
+	require.NoError(t, client.UpdateHTTPCheck(check))
+	assert.True(t, client.httpChecks[ownerTagFromName("other/foo")].spec.Paused)
+}
+
+func TestUpdateHTTPCheckRecreatesDeletedCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		check = v1alpha1.HTTPCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "other",
+			},
+			Spec: v1alpha1.HTTPCheckSpec{
+				Hostname: "foo.io",
+			},
+		}
+
+		checks    = NewMockcheckService(ctrl)
+		cli       = NewMockpingdomClient(ctrl)
+		recreated []v1alpha1.HTTPCheck
+	)
+
+	// The check was deleted in Pingdom, so updating its cached ID fails and it's created again.
+	gomock.InOrder(
+		checks.EXPECT().Update(42, gomock.Any()).Return(nil, &pingdom.PingdomError{StatusCode: 404}),
+		checks.EXPECT().Create(gomock.Any()).Return(&pingdom.CheckResponse{ID: 43}, nil),
+	)
+	cli.EXPECT().Checks().Times(2).Return(checks)
+
+	client := Client{
+		client: cli,
+		httpChecks: map[string]httpCheck{
+			ownerTagFromName("other/foo"): {id: 42, name: "other/foo"},
+		},
+		onRecreate: func(check v1alpha1.HTTPCheck) { recreated = append(recreated, check) },
+		logger:     zap.NewNop(),
+	}
+
+	require.NoError(t, client.UpdateHTTPCheck(check))
+	assert.Equal(t, 43, client.httpChecks[ownerTagFromName("other/foo")].id)
+	require.Len(t, recreated, 1)
+	assert.Equal(t, "foo", recreated[0].Name)
+}
+
+func TestUpdateHTTPCheckRecreateFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		check = v1alpha1.HTTPCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "other",
+			},
+		}
+
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+	)
+
+	gomock.InOrder(
+		checks.EXPECT().Update(42, gomock.Any()).Return(nil, &pingdom.PingdomError{StatusCode: 404}),
+		checks.EXPECT().Create(gomock.Any()).Return(nil, &pingdom.PingdomError{StatusCode: 503}),
+	)
+	cli.EXPECT().Checks().Times(2).Return(checks)
+
+	client := Client{
+		client: cli,
+		httpChecks: map[string]httpCheck{
+			ownerTagFromName("other/foo"): {id: 42, name: "other/foo"},
+		},
+		logger: zap.NewNop(),
+	}
+
+	// The stale entry is dropped, so the check is created when it's retried.
+	err := client.UpdateHTTPCheck(check)
+	require.Error(t, err)
+	assert.False(t, IsPermanent(err))
+	assert.Empty(t, client.httpChecks)
+}
+
+func TestUpdateHTTPCheckSkipsNoOp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		check = v1alpha1.HTTPCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "other",
+				Labels:    map[string]string{"app": "foo"},
+			},
+			Spec: v1alpha1.HTTPCheckSpec{
+				Hostname:        "foo.io",
+				IntervalMinutes: 5,
+				Tags:            []string{"Web"},
+			},
+		}
+
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+	)
+
+	// Only the first update reaches Pingdom, since the check doesn't change afterwards.
+	checks.EXPECT().Update(42, gomock.Any())
+	cli.EXPECT().Checks().Return(checks)
+
+	client := Client{
+		client: cli,
+		httpChecks: map[string]httpCheck{
+			ownerTagFromName("other/foo"): {id: 42, name: "other/foo"},
+		},
+		logger: zap.NewNop(),
+	}
+
+	require.NoError(t, client.UpdateHTTPCheck(check))
+	assert.Equal(t, []string{"web"}, client.httpChecks[ownerTagFromName("other/foo")].spec.Tags)
+
+	// Labels which aren't added as tags, and the status, don't change the check in Pingdom.
+	check.Labels["version"] = "2"
+	check.Status.State = "successfully updated check"
+	require.NoError(t, client.UpdateHTTPCheck(check))
+}
+
+func TestUpdateHTTPCheckReadFromPingdom(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		spec = v1alpha1.HTTPCheckSpec{
+			Hostname:        "foo.io",
+			IntervalMinutes: 5,
+		}
+		check = v1alpha1.HTTPCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "other",
+			},
+			Spec: spec,
+		}
+
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+	)
+
+	// Pingdom doesn't return who a check alerts, so a check read from Pingdom is updated once
+	// even if its spec matches, and the update after that is skipped.
+	checks.EXPECT().Update(42, gomock.Any())
+	cli.EXPECT().Checks().Return(checks)
+
+	client := Client{
+		client: cli,
+		httpChecks: map[string]httpCheck{
+			ownerTagFromName("other/foo"): {id: 42, name: "other/foo", spec: spec},
+		},
+		logger: zap.NewNop(),
 	}
-	assert.Equal(t, expected, client.httpChecks[name])
+
+	require.NoError(t, client.UpdateHTTPCheck(check))
+	require.NoError(t, client.UpdateHTTPCheck(check))
 }
 
 func TestDeleteHTTPCheck(t *testing.T) {
@@ -317,7 +651,7 @@ func TestDeleteHTTPCheck(t *testing.T) {
 	client := Client{
 		client: cli,
 		httpChecks: map[string]httpCheck{
-			"default/foo": {
+			ownerTagFromName("default/foo"): {
 				id: id,
 			},
 		},
@@ -333,3 +667,249 @@ func TestDeleteHTTPCheck(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, client.httpChecks, 0)
 }
+
+func TestDeleteHTTPCheckNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		id     = 42
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+	)
+
+	// A check deleted outside of heimdallr is forgotten as if it was deleted by us.
+	checks.EXPECT().Delete(id).Return(nil, &pingdom.PingdomError{StatusCode: 404, Message: "Check not found"})
+	cli.EXPECT().Checks().Return(checks)
+
+	client := Client{
+		client: cli,
+		httpChecks: map[string]httpCheck{
+			ownerTagFromName("default/foo"): {
+				id: id,
+			},
+		},
+		logger: zap.NewNop(),
+	}
+
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "foo",
+		},
+	}
+	require.NoError(t, client.DeleteHTTPCheck(check))
+	assert.Len(t, client.httpChecks, 0)
+}
+
+func TestDeleteOrphanedHTTPChecks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+	)
+
+	// Only the check whose HTTPCheck no longer exists is deleted, even though the client didn't
+	// create it, since it's owned by the client's cluster.
+	checks.EXPECT().Delete(43)
+	cli.EXPECT().Checks().Return(checks)
+
+	client := Client{
+		client:  cli,
+		cluster: "us-east-1",
+		httpChecks: map[string]httpCheck{
+			ownerTagFromName("us-east-1/default/foo"): {id: 42, name: "us-east-1/default/foo"},
+			ownerTagFromName("us-east-1/default/bar"): {id: 43, name: "us-east-1/default/bar"},
+		},
+		logger: zap.NewNop(),
+	}
+
+	err := client.DeleteOrphanedHTTPChecks(func() ([]v1alpha1.HTTPCheck, error) {
+		return []v1alpha1.HTTPCheck{
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}},
+		}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]httpCheck{
+		ownerTagFromName("us-east-1/default/foo"): {id: 42, name: "us-east-1/default/foo"},
+	}, client.httpChecks)
+}
+
+func TestDeleteOrphanedHTTPChecksListFails(t *testing.T) {
+	client := Client{
+		httpChecks: map[string]httpCheck{
+			ownerTagFromName("default/foo"): {id: 42, name: "default/foo"},
+		},
+		logger: zap.NewNop(),
+	}
+
+	// Nothing is deleted if the HTTPChecks can't be listed.
+	err := client.DeleteOrphanedHTTPChecks(func() ([]v1alpha1.HTTPCheck, error) {
+		return nil, errors.New("connection refused")
+	})
+	assert.EqualError(t, err, "failed to list checks: connection refused")
+	assert.Len(t, client.httpChecks, 1)
+}
+
+func TestUpdateHTTPCheckDryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		spec = v1alpha1.HTTPCheckSpec{
+			Hostname:        "foo.io",
+			IntervalMinutes: 10,
+		}
+		check = v1alpha1.HTTPCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "other",
+			},
+			Spec: spec,
+		}
+		name = "other/foo"
+
+		// No calls are expected to be made to Pingdom.
+		cli = NewMockpingdomClient(ctrl)
+
+		created = operations.WithLabelValues(string(ActionCreate), "true")
+		updated = operations.WithLabelValues(string(ActionUpdate), "true")
+		deleted = operations.WithLabelValues(string(ActionDelete), "true")
+	)
+
+	client := Client{
+		client:     cli,
+		httpChecks: map[string]httpCheck{},
+		dryRun:     true,
+		logger:     zap.NewNop(),
+	}
+
+	before := testutil.ToFloat64(created)
+	require.NoError(t, client.UpdateHTTPCheck(check))
+	assert.Equal(t, httpCheck{name: name, spec: spec, rcpts: &recipients{}}, client.httpChecks[ownerTagFromName(name)])
+	assert.Equal(t, before+1, testutil.ToFloat64(created))
+
+	check.Spec.IntervalMinutes = 5
+	before = testutil.ToFloat64(updated)
+	require.NoError(t, client.UpdateHTTPCheck(check))
+	assert.Equal(t, check.Spec, client.httpChecks[ownerTagFromName(name)].spec)
+	assert.Equal(t, before+1, testutil.ToFloat64(updated))
+
+	before = testutil.ToFloat64(deleted)
+	require.NoError(t, client.DeleteHTTPCheck(check))
+	assert.Len(t, client.httpChecks, 0)
+	assert.Equal(t, before+1, testutil.ToFloat64(deleted))
+}
+
+func TestListUnmanagedHTTPChecks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+
+		managed = pingdom.CheckResponse{
+			ID:   71,
+			Name: "default/foo",
+			Type: pingdom.CheckResponseType{Name: "http"},
+			Tags: []pingdom.CheckResponseTag{
+				{
+					Name: heimdallrTag,
+				},
+			},
+		}
+		ping = pingdom.CheckResponse{
+			ID:   82,
+			Name: "ping",
+			Type: pingdom.CheckResponseType{Name: "ping"},
+		}
+		unmanaged = pingdom.CheckResponse{
+			ID:         93,
+			Name:       "My Website",
+			Hostname:   "example.com",
+			Resolution: 5,
+			Type: pingdom.CheckResponseType{
+				Name: "http",
+				HTTP: &pingdom.CheckResponseHTTPDetails{
+					Url:        "/health",
+					Encryption: true,
+				},
+			},
+		}
+	)
+
+	checks.EXPECT().
+		List(map[string]string{"include_tags": "true", "limit": "25000", "offset": "0"}).
+		Return([]pingdom.CheckResponse{managed, ping, unmanaged}, nil)
+
+	checks.EXPECT().
+		Read(unmanaged.ID).
+		Return(&unmanaged, nil)
+
+	cli.EXPECT().Checks().Times(2).Return(checks)
+
+	client := Client{
+		client:     cli,
+		httpChecks: make(map[string]httpCheck),
+		logger:     zap.NewNop(),
+	}
+
+	list, err := client.ListUnmanagedHTTPChecks()
+	require.NoError(t, err)
+
+	expected := []UnmanagedHTTPCheck{
+		{
+			ID:   93,
+			Name: "My Website",
+			Spec: v1alpha1.HTTPCheckSpec{
+				Hostname:        "example.com",
+				URL:             "/health",
+				IntervalMinutes: 5,
+				EnableTLS:       true,
+			},
+		},
+	}
+	assert.Equal(t, expected, list)
+}
+
+func TestAdoptHTTPCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		id    = 93
+		check = v1alpha1.HTTPCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-website",
+				Namespace: "web",
+			},
+			Spec: v1alpha1.HTTPCheckSpec{
+				Hostname:        "example.com",
+				IntervalMinutes: 5,
+			},
+		}
+
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+	)
+
+	checks.EXPECT().Update(id, gomock.Any()).Do(func(_ int, pc pingdom.Check) {
+		hc := pc.(*pingdom.HttpCheck)
+		assert.Equal(t, "web/my-website", hc.Name)
+		assert.Equal(t, heimdallrTag+","+ownerTagFromName("web/my-website"), hc.Tags)
+	})
+	cli.EXPECT().Checks().Return(checks)
+
+	client := Client{
+		client:     cli,
+		httpChecks: make(map[string]httpCheck),
+		logger:     zap.NewNop(),
+	}
+
+	require.NoError(t, client.AdoptHTTPCheck(id, check))
+	assert.Equal(t, id, client.httpChecks[ownerTagFromName("web/my-website")].id)
+
+	assert.Error(t, client.AdoptHTTPCheck(id, check))
+}
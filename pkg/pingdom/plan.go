@@ -0,0 +1,229 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+)
+
+// Action is an operation heimdallr performs on a Pingdom check.
+type Action string
+
+const (
+	// ActionCreate indicates a check will be created.
+	ActionCreate Action = "create"
+
+	// ActionUpdate indicates a check will be updated.
+	ActionUpdate Action = "update"
+
+	// ActionDelete indicates a check will be deleted.
+	ActionDelete Action = "delete"
+)
+
+// FieldDiff is a difference between the current and desired value of a field of a check.
+type FieldDiff struct {
+	Field   string
+	Current string
+	Desired string
+}
+
+// Change is a change heimdallr would make to a Pingdom check.
+type Change struct {
+	Action Action
+	Name   string
+	Diffs  []FieldDiff
+}
+
+// Plan returns the changes that would be made to Pingdom to bring it in line with the given
+// checks. Checks managed by heimdallr which don't correspond to any of the given checks are
+// planned for deletion.
+func (c *Client) Plan(checks []v1alpha1.HTTPCheck) ([]Change, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var (
+		changes []Change
+		desired = make(map[string]bool, len(checks))
+	)
+
+	for _, check := range checks {
+		key := c.ownerTag(check)
+		desired[key] = true
+
+		name, err := c.checkName(check)
+		if err != nil {
+			return nil, fmt.Errorf("failed to name check %v: %v", getName(check), err)
+		}
+		spec, _, err := c.resolve(check.Namespace, check.Spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve check %v: %v", getName(check), err)
+		}
+		check.Spec = c.withDefaults(spec)
+		check.Spec.Tags = c.userTags(check)
+
+		hc, ok := c.httpChecks[key]
+		if !ok {
+			changes = append(changes, Change{
+				Action: ActionCreate,
+				Name:   name,
+				Diffs:  diffSpec(v1alpha1.HTTPCheckSpec{}, check.Spec),
+			})
+			continue
+		}
+
+		current := hc.spec
+		if hc.listed {
+			current = withListedFields(check.Spec, hc.spec)
+			if hc.name == name && len(diffSpec(current, check.Spec)) == 0 {
+				// The fields the list of checks includes match, so the details of the check are
+				// read to compare the others.
+				if hc, err = c.readHTTPCheck(hc.id); err != nil {
+					return nil, err
+				}
+				current = hc.spec
+			}
+		}
+
+		diffs := diffSpec(current, check.Spec)
+		if hc.name != name {
+			diffs = append([]FieldDiff{{Field: "name", Current: hc.name, Desired: name}}, diffs...)
+		}
+		if len(diffs) > 0 {
+			changes = append(changes, Change{
+				Action: ActionUpdate,
+				Name:   name,
+				Diffs:  diffs,
+			})
+		}
+	}
+
+	for key, hc := range c.httpChecks {
+		if !desired[key] {
+			changes = append(changes, Change{
+				Action: ActionDelete,
+				Name:   hc.name,
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes, nil
+}
+
+// diffSpec returns the fields whose values differ between two specs.
+func diffSpec(current, desired v1alpha1.HTTPCheckSpec) []FieldDiff {
+	current, desired = normalizeSpec(current), normalizeSpec(desired)
+
+	fields := []struct {
+		name    string
+		current interface{}
+		desired interface{}
+	}{
+		{"type", current.Type, desired.Type},
+		{"hostname", current.Hostname, desired.Hostname},
+		{"url", current.URL, desired.URL},
+		{"port", current.Port, desired.Port},
+		{"intervalMinutes", current.IntervalMinutes, desired.IntervalMinutes},
+		{"triggerThreshold", current.TriggerThreshold, desired.TriggerThreshold},
+		{"retriggerThreshold", current.RetriggerThreshold, desired.RetriggerThreshold},
+		{"notifyWhenBackup", current.NotifyWhenBackup, desired.NotifyWhenBackup},
+		{"enableTLS", current.EnableTLS, desired.EnableTLS},
+		{"integrationIDs", current.IntegrationIDs, desired.IntegrationIDs},
+		{"tags", current.Tags, desired.Tags},
+		{"paused", current.Paused, desired.Paused},
+	}
+
+	var diffs []FieldDiff
+	for _, f := range fields {
+		cur, des := fmt.Sprint(f.current), fmt.Sprint(f.desired)
+		if cur != des {
+			diffs = append(diffs, FieldDiff{
+				Field:   f.name,
+				Current: cur,
+				Desired: des,
+			})
+		}
+	}
+	return diffs
+}
+
+// diffCheck returns the differences between the cached state of a check and the given name, spec
+// and recipients, which are empty if the check doesn't need to be updated.
+func diffCheck(hc httpCheck, name string, spec v1alpha1.HTTPCheckSpec, rcpts recipients) []FieldDiff {
+	current := hc.spec
+	if hc.listed {
+		current = withListedFields(spec, hc.spec)
+	}
+
+	diffs := diffSpec(current, spec)
+	if hc.name != name {
+		diffs = append([]FieldDiff{{Field: "name", Current: hc.name, Desired: name}}, diffs...)
+	}
+
+	if hc.rcpts == nil {
+		return append(diffs, FieldDiff{Field: "recipients", Current: "unknown", Desired: rcpts.String()})
+	}
+	if cur, des := hc.rcpts.String(), rcpts.String(); cur != des {
+		diffs = append(diffs, FieldDiff{Field: "recipients", Current: cur, Desired: des})
+	}
+	return diffs
+}
+
+// normalizeSpec replaces unset fields of a spec which Pingdom defaults with their defaults so
+// that specs can be compared with the state read back from Pingdom.
+func normalizeSpec(spec v1alpha1.HTTPCheckSpec) v1alpha1.HTTPCheckSpec {
+	if spec.Hostname == "" {
+		// The spec is empty, as it is for a check which doesn't exist yet.
+		return spec
+	}
+
+	if spec.Type == v1alpha1.CheckTypeHTTP {
+		spec.Type = ""
+	}
+	if spec.Type == v1alpha1.CheckTypeTCP {
+		// TCP checks have neither a URL nor TLS.
+		spec.URL = ""
+		spec.EnableTLS = false
+	} else if spec.URL == "" {
+		spec.URL = "/"
+	}
+	if spec.Port == 0 {
+		spec.Port = 80
+		if spec.EnableTLS {
+			spec.Port = 443
+		}
+	}
+
+	ids := make([]int, len(spec.IntegrationIDs))
+	copy(ids, spec.IntegrationIDs)
+	sort.Ints(ids)
+	spec.IntegrationIDs = ids
+
+	tags := make([]string, len(spec.Tags))
+	copy(tags, spec.Tags)
+	sort.Strings(tags)
+	spec.Tags = tags
+
+	return spec
+}
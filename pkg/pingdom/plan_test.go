@@ -0,0 +1,247 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"testing"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/golang/mock/gomock"
+	"github.com/russellcardullo/go-pingdom/pingdom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPlan(t *testing.T) {
+	var (
+		spec = v1alpha1.HTTPCheckSpec{
+			Hostname:         "foo.io",
+			IntervalMinutes:  5,
+			TriggerThreshold: 2,
+			EnableTLS:        true,
+			IntegrationIDs:   []int{3, 1},
+		}
+		client = Client{
+			httpChecks: map[string]httpCheck{
+				ownerTagFromName("default/unchanged"): {
+					id:   1,
+					name: "default/unchanged",
+					spec: v1alpha1.HTTPCheckSpec{
+						Hostname:         "foo.io",
+						URL:              "/",
+						Port:             443,
+						IntervalMinutes:  5,
+						TriggerThreshold: 2,
+						EnableTLS:        true,
+						IntegrationIDs:   []int{1, 3},
+					},
+				},
+				ownerTagFromName("default/changed"): {
+					id:   2,
+					name: "default/changed",
+					spec: spec,
+				},
+				ownerTagFromName("default/deleted"): {
+					id:   3,
+					name: "default/deleted",
+					spec: spec,
+				},
+			},
+			logger: zap.NewNop(),
+		}
+		changed = spec
+	)
+	changed.IntervalMinutes = 1
+	changed.Tags = []string{"team_payments"}
+
+	checks := []v1alpha1.HTTPCheck{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "unchanged"},
+			Spec:       spec,
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "changed"},
+			Spec:       changed,
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "created", Namespace: "web"},
+			Spec:       v1alpha1.HTTPCheckSpec{Hostname: "bar.com"},
+		},
+	}
+
+	expected := []Change{
+		{
+			Action: ActionUpdate,
+			Name:   "default/changed",
+			Diffs: []FieldDiff{
+				{Field: "intervalMinutes", Current: "5", Desired: "1"},
+				{Field: "tags", Current: "[]", Desired: "[team_payments]"},
+			},
+		},
+		{
+			Action: ActionDelete,
+			Name:   "default/deleted",
+		},
+		{
+			Action: ActionCreate,
+			Name:   "web/created",
+			Diffs: []FieldDiff{
+				{Field: "hostname", Current: "", Desired: "bar.com"},
+				{Field: "url", Current: "", Desired: "/"},
+				{Field: "port", Current: "0", Desired: "80"},
+			},
+		},
+	}
+	changes, err := client.Plan(checks)
+	require.NoError(t, err)
+	assert.Equal(t, expected, changes)
+}
+
+func TestPlanRename(t *testing.T) {
+	var (
+		spec  = v1alpha1.HTTPCheckSpec{Hostname: "foo.io", URL: "/", Port: 80}
+		check = v1alpha1.HTTPCheck{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "web"},
+			Spec:       spec,
+		}
+		client = Client{
+			httpChecks: map[string]httpCheck{
+				ownerTagFromName("web/foo"): {
+					id:   1,
+					name: "web/foo",
+					spec: spec,
+				},
+			},
+			logger: zap.NewNop(),
+		}
+	)
+
+	tmpl, err := ParseNameTemplate("{{.Namespace}}/{{.Name}} {{.Spec.Hostname}}")
+	require.NoError(t, err)
+	WithNameTemplate(tmpl)(&client)
+
+	// Changing the name template renames checks rather than recreating them.
+	changes, err := client.Plan([]v1alpha1.HTTPCheck{check})
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{
+			Action: ActionUpdate,
+			Name:   "web/foo foo.io",
+			Diffs:  []FieldDiff{{Field: "name", Current: "web/foo", Desired: "web/foo foo.io"}},
+		},
+	}, changes)
+}
+
+func TestPlanListedChecks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+		spec   = v1alpha1.HTTPCheckSpec{Hostname: "foo.io", IntervalMinutes: 5, TriggerThreshold: 2}
+		client = Client{
+			client: cli,
+			httpChecks: map[string]httpCheck{
+				ownerTagFromName("default/moved"): {
+					id:     1,
+					name:   "default/moved",
+					spec:   v1alpha1.HTTPCheckSpec{Hostname: "bar.com", IntervalMinutes: 5},
+					listed: true,
+				},
+				ownerTagFromName("default/listed"): {
+					id:     2,
+					name:   "default/listed",
+					spec:   v1alpha1.HTTPCheckSpec{Hostname: "foo.io", IntervalMinutes: 5},
+					listed: true,
+				},
+			},
+			logger: zap.NewNop(),
+		}
+	)
+
+	// Only the check whose listed fields match is read to compare the fields the list doesn't
+	// include.
+	checks.EXPECT().
+		Read(2).
+		Return(&pingdom.CheckResponse{
+			ID:                       2,
+			Name:                     "default/listed",
+			Hostname:                 "foo.io",
+			Resolution:               5,
+			SendNotificationWhenDown: 3,
+			Type: pingdom.CheckResponseType{
+				HTTP: &pingdom.CheckResponseHTTPDetails{Url: "/", Port: 80},
+			},
+		}, nil)
+	cli.EXPECT().Checks().Return(checks)
+
+	changes, err := client.Plan([]v1alpha1.HTTPCheck{
+		{ObjectMeta: metav1.ObjectMeta{Name: "moved"}, Spec: spec},
+		{ObjectMeta: metav1.ObjectMeta{Name: "listed"}, Spec: spec},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{
+			Action: ActionUpdate,
+			Name:   "default/listed",
+			Diffs:  []FieldDiff{{Field: "triggerThreshold", Current: "3", Desired: "2"}},
+		},
+		{
+			Action: ActionUpdate,
+			Name:   "default/moved",
+			Diffs:  []FieldDiff{{Field: "hostname", Current: "bar.com", Desired: "foo.io"}},
+		},
+	}, changes)
+}
+
+func TestDiffCheck(t *testing.T) {
+	var (
+		spec  = v1alpha1.HTTPCheckSpec{Hostname: "foo.io", IntegrationIDs: []int{2, 1}}
+		rcpts = recipients{userIDs: []int{3, 4}, teamIDs: []int{5}}
+		hc    = httpCheck{
+			name:  "web/foo",
+			spec:  v1alpha1.HTTPCheckSpec{Hostname: "foo.io", URL: "/", Port: 80, IntegrationIDs: []int{1, 2}},
+			rcpts: &recipients{userIDs: []int{4, 3}, teamIDs: []int{5}},
+		}
+	)
+	assert.Empty(t, diffCheck(hc, "web/foo", spec, rcpts), "expected defaults and ordering to be ignored")
+
+	assert.Equal(t, []FieldDiff{
+		{Field: "name", Current: "web/foo", Desired: "web/bar"},
+		{Field: "recipients", Current: "users=[3 4] teams=[5]", Desired: "users=[3] teams=[5]"},
+	}, diffCheck(hc, "web/bar", spec, recipients{userIDs: []int{3}, teamIDs: []int{5}}))
+
+	hc.rcpts = nil
+	assert.Equal(t, []FieldDiff{
+		{Field: "recipients", Current: "unknown", Desired: "users=[3 4] teams=[5]"},
+	}, diffCheck(hc, "web/foo", spec, rcpts))
+
+	// A listed check is only compared on the fields the list includes.
+	hc = httpCheck{name: "web/foo", spec: v1alpha1.HTTPCheckSpec{Hostname: "bar.com"}, listed: true}
+	assert.Equal(t, []FieldDiff{
+		{Field: "hostname", Current: "bar.com", Desired: "foo.io"},
+		{Field: "recipients", Current: "unknown", Desired: "users=[3 4] teams=[5]"},
+	}, diffCheck(hc, "web/foo", spec, rcpts))
+}
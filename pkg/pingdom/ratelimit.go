@@ -0,0 +1,260 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// defaultRequestRate and defaultRequestBurst limit the requests made to Pingdom until its
+	// rate limit headers say otherwise.
+	defaultRequestRate  = 5
+	defaultRequestBurst = 10
+
+	// maxRateLimitRetries is the number of times a request which was rate limited is retried.
+	maxRateLimitRetries = 3
+
+	// rateLimitBackoff is the delay before the first retry of a rate limited request which
+	// doesn't say when to retry, doubled for each later retry.
+	rateLimitBackoff = 5 * time.Second
+)
+
+// reqLimitPattern matches the Req-Limit-Short and Req-Limit-Long headers of Pingdom responses,
+// such as "Remaining: 394 Time until reset: 3589".
+var reqLimitPattern = regexp.MustCompile(`Remaining:\s*(\d+)\s*Time until reset:\s*(\d+)`)
+
+// WithRateLimit configures the maximum rate, in requests per second, and burst of requests made
+// to Pingdom. The rate is lowered when Pingdom reports that fewer requests remain.
+func WithRateLimit(limit float64, burst int) Option {
+	return func(c *Client) {
+		if c.transport != nil {
+			c.transport.setMax(rate.Limit(limit), burst)
+		}
+	}
+}
+
+// rateLimitTransport limits the rate of requests to stay within Pingdom's rate limits. The rate
+// adapts to the requests Pingdom reports are remaining, requests are held back until the limit
+// resets once none remain, and requests which are rate limited anyway are retried after a delay.
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	limiter *rate.Limiter
+	wait    func(ctx context.Context, d time.Duration) error
+	now     func() time.Time
+
+	mu      sync.Mutex
+	max     rate.Limit
+	blocked time.Time
+}
+
+func newRateLimitTransport(base http.RoundTripper) *rateLimitTransport {
+	return &rateLimitTransport{
+		base:    base,
+		limiter: rate.NewLimiter(defaultRequestRate, defaultRequestBurst),
+		wait:    waitContext,
+		now:     time.Now,
+		max:     defaultRequestRate,
+	}
+}
+
+// setMax sets the maximum rate and burst of requests. The limiter is only replaced if they
+// changed, so that configuring another client sharing the transport doesn't reset its state.
+func (t *rateLimitTransport) setMax(limit rate.Limit, burst int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.max == limit && t.limiter.Burst() == burst {
+		return
+	}
+	t.max = limit
+	t.limiter = rate.NewLimiter(limit, burst)
+}
+
+// transports holds the rate limited transport of each set of credentials, so that every client
+// of an account, such as those recreated when credentials are reloaded, shares its rate limits.
+var transports = struct {
+	sync.Mutex
+	byKey map[string]*rateLimitTransport
+}{byKey: make(map[string]*rateLimitTransport)}
+
+// sharedRateLimitTransport returns the rate limited transport of the credentials identified by
+// key, creating it with the base transport returned by newBase if there isn't one yet.
+func sharedRateLimitTransport(key string, newBase func() http.RoundTripper) *rateLimitTransport {
+	transports.Lock()
+	defer transports.Unlock()
+
+	t, ok := transports.byKey[key]
+	if !ok {
+		t = newRateLimitTransport(newBase())
+		transports.byKey[key] = t
+	}
+	return t
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if d := t.blockedFor(); d > 0 {
+			if err := t.wait(req.Context(), d); err != nil {
+				return nil, err
+			}
+		}
+		if err := t.currentLimiter().Wait(req.Context()); err != nil {
+			return nil, err
+		}
+
+		r, err := rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := t.base.RoundTrip(r)
+		if err != nil {
+			return nil, err
+		}
+		t.adapt(resp.Header)
+
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= maxRateLimitRetries ||
+			(req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+
+		recordRateLimited()
+		t.block(retryAfter(resp.Header, rateLimitBackoff<<uint(attempt)))
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
+
+// adapt lowers the rate of requests so that the remaining requests Pingdom reports in the
+// headers of a response last until its limits reset, and holds back requests until the limit
+// resets if none remain.
+func (t *rateLimitTransport) adapt(header http.Header) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	limit := t.max
+	for _, name := range []string{"Req-Limit-Short", "Req-Limit-Long"} {
+		remaining, reset, ok := parseReqLimit(header.Get(name))
+		if !ok {
+			continue
+		}
+		if remaining == 0 {
+			t.blockUntil(t.now().Add(reset))
+			continue
+		}
+		if l := rate.Limit(float64(remaining) / reset.Seconds()); reset > 0 && l < limit {
+			limit = l
+		}
+	}
+	t.limiter.SetLimit(limit)
+}
+
+// block holds back requests for the given duration.
+func (t *rateLimitTransport) block(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.blockUntil(t.now().Add(d))
+}
+
+func (t *rateLimitTransport) blockUntil(until time.Time) {
+	if until.After(t.blocked) {
+		t.blocked = until
+	}
+}
+
+func (t *rateLimitTransport) blockedFor() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if d := t.blocked.Sub(t.now()); d > 0 {
+		return d
+	}
+	return 0
+}
+
+func (t *rateLimitTransport) currentLimiter() *rate.Limiter {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.limiter
+}
+
+// waitContext waits for the given duration, or until the context is done.
+func waitContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// parseReqLimit parses the remaining requests and the time until the limit resets from a
+// Req-Limit-Short or Req-Limit-Long header.
+func parseReqLimit(value string) (int, time.Duration, bool) {
+	m := reqLimitPattern.FindStringSubmatch(value)
+	if m == nil {
+		return 0, 0, false
+	}
+	remaining, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	reset, err := strconv.Atoi(m[2])
+	if err != nil {
+		return 0, 0, false
+	}
+	return remaining, time.Duration(reset) * time.Second, true
+}
+
+// retryAfter returns the delay given by the Retry-After header of a response, or the fallback
+// if it doesn't have one.
+func retryAfter(header http.Header, fallback time.Duration) time.Duration {
+	if s, err := strconv.Atoi(header.Get("Retry-After")); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second
+	}
+	return fallback
+}
+
+// rewind returns the request to send for the given attempt, with a fresh copy of its body for
+// retries.
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r := req.WithContext(req.Context())
+	r.Body = body
+	return r, nil
+}
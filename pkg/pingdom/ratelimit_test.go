@@ -0,0 +1,187 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func newTestRateLimitTransport(now time.Time) (*rateLimitTransport, *[]time.Duration) {
+	var slept []time.Duration
+	t := newRateLimitTransport(http.DefaultTransport)
+	t.limiter = rate.NewLimiter(rate.Inf, 1)
+	t.max = rate.Inf
+	t.now = func() time.Time { return now }
+	t.wait = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		now = now.Add(d)
+		return nil
+	}
+	return t, &slept
+}
+
+func TestParseReqLimit(t *testing.T) {
+	remaining, reset, ok := parseReqLimit("Remaining: 394 Time until reset: 3589")
+	require.True(t, ok)
+	assert.Equal(t, 394, remaining)
+	assert.Equal(t, 3589*time.Second, reset)
+
+	_, _, ok = parseReqLimit("")
+	assert.False(t, ok)
+	_, _, ok = parseReqLimit("Remaining: many")
+	assert.False(t, ok)
+}
+
+func TestRateLimitTransportAdapt(t *testing.T) {
+	now := time.Unix(1000, 0)
+	transport, _ := newTestRateLimitTransport(now)
+	transport.max = 10
+	transport.limiter.SetLimit(10)
+
+	header := http.Header{}
+	header.Set("Req-Limit-Short", "Remaining: 100 Time until reset: 50")
+	header.Set("Req-Limit-Long", "Remaining: 3600 Time until reset: 3600")
+	transport.adapt(header)
+	assert.Equal(t, rate.Limit(1), transport.limiter.Limit())
+	assert.Equal(t, time.Duration(0), transport.blockedFor())
+
+	// The rate is raised again, up to the maximum, once more requests remain.
+	header.Set("Req-Limit-Short", "Remaining: 1000 Time until reset: 10")
+	transport.adapt(header)
+	assert.Equal(t, rate.Limit(1), transport.limiter.Limit())
+	header.Set("Req-Limit-Long", "Remaining: 36000 Time until reset: 60")
+	transport.adapt(header)
+	assert.Equal(t, rate.Limit(10), transport.limiter.Limit())
+
+	// Requests are held back until the limit resets once none remain.
+	header.Set("Req-Limit-Short", "Remaining: 0 Time until reset: 30")
+	transport.adapt(header)
+	assert.Equal(t, 30*time.Second, transport.blockedFor())
+}
+
+func TestRateLimitTransportRetries(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		bodies = append(bodies, string(b))
+
+		switch len(bodies) {
+		case 1:
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	transport, slept := newTestRateLimitTransport(time.Unix(1000, 0))
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("name=test"))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"name=test", "name=test", "name=test"}, bodies)
+	assert.Equal(t, []time.Duration{7 * time.Second, 2 * rateLimitBackoff}, *slept)
+}
+
+func TestRateLimitTransportGivesUp(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	transport, slept := newTestRateLimitTransport(time.Unix(1000, 0))
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, maxRateLimitRetries+1, requests)
+	assert.Len(t, *slept, maxRateLimitRetries)
+}
+
+func TestWithRateLimit(t *testing.T) {
+	transport := newRateLimitTransport(http.DefaultTransport)
+	c := &Client{}
+	for _, opt := range withTransport(transport, []Option{WithRateLimit(2, 4)}) {
+		opt(c)
+	}
+
+	assert.Equal(t, transport, c.transport)
+	assert.Equal(t, rate.Limit(2), transport.limiter.Limit())
+	assert.Equal(t, 4, transport.limiter.Burst())
+}
+
+func TestRateLimitTransportCancelled(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	// A request held back until the limit resets returns once it's cancelled.
+	transport := newRateLimitTransport(http.DefaultTransport)
+	transport.block(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequest("GET", server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = transport.RoundTrip(req.WithContext(ctx))
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 0, requests)
+}
+
+func TestSharedRateLimitTransport(t *testing.T) {
+	base := func() http.RoundTripper { return http.DefaultTransport }
+
+	// Clients of the same account share a transport, and so its rate limits.
+	a := sharedRateLimitTransport("token:shared-a", base)
+	assert.Equal(t, a, sharedRateLimitTransport("token:shared-a", base))
+	assert.NotEqual(t, a, sharedRateLimitTransport("token:shared-b", base))
+
+	// Configuring another client with the same limits keeps the adapted rate.
+	a.setMax(2, 4)
+	a.limiter.SetLimit(1)
+	a.setMax(2, 4)
+	assert.Equal(t, rate.Limit(1), a.limiter.Limit())
+}
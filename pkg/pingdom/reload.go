@@ -0,0 +1,270 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"go.uber.org/zap"
+)
+
+// ReloadingClient is a client whose credentials can be replaced while it's in use.
+type ReloadingClient struct {
+	factory func(creds Credentials) (checkClient, error)
+	logger  *zap.Logger
+
+	sync.RWMutex
+	client checkClient
+	creds  Credentials
+	err    error
+
+	// authErr is the error of the last call rejected by Pingdom because of the credentials, such
+	// as after they were revoked, until a later call succeeds.
+	authErr error
+}
+
+// NewReloadingClient creates a new ReloadingClient which creates clients with the given factory.
+// No client is created until credentials are loaded with Reload.
+func NewReloadingClient(factory func(creds Credentials) (*Client, error), logger *zap.Logger) *ReloadingClient {
+	return newReloadingClient(func(creds Credentials) (checkClient, error) {
+		client, err := factory(creds)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	}, logger)
+}
+
+func newReloadingClient(factory func(creds Credentials) (checkClient, error), logger *zap.Logger) *ReloadingClient {
+	return &ReloadingClient{
+		factory: factory,
+		logger:  logger,
+		err:     errors.New("no credentials loaded"),
+	}
+}
+
+// Reload replaces the current client with one using the given credentials, unless they're the
+// credentials of the current client. The current client is kept if the credentials are invalid.
+func (r *ReloadingClient) Reload(creds Credentials) error {
+	r.RLock()
+	unchanged := r.client != nil && r.err == nil && r.creds == creds
+	r.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	client, err := r.factory(creds)
+	if err != nil {
+		err = fmt.Errorf("failed to create client from credentials: %v", err)
+		r.setErr(err)
+		return err
+	}
+
+	r.Lock()
+	r.client = client
+	r.creds = creds
+	r.err = nil
+	r.authErr = nil
+	r.Unlock()
+
+	r.logger.Info("loaded pingdom credentials")
+	return nil
+}
+
+// Watch reloads the credentials returned by load every interval until stop is closed.
+func (r *ReloadingClient) Watch(stop <-chan struct{}, interval time.Duration, load func() (Credentials, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		creds, err := load()
+		if err != nil {
+			err = fmt.Errorf("failed to load credentials: %v", err)
+			r.setErr(err)
+			r.logger.Error("unable to reload pingdom credentials", zap.Error(err))
+			continue
+		}
+
+		if err := r.Reload(creds); err != nil {
+			r.logger.Error("unable to reload pingdom credentials", zap.Error(err))
+		}
+	}
+}
+
+// Ready returns an error if the most recently loaded credentials couldn't be used, or if Pingdom
+// rejected them since.
+func (r *ReloadingClient) Ready() error {
+	r.RLock()
+	defer r.RUnlock()
+	if r.err != nil {
+		return r.err
+	}
+	return r.authErr
+}
+
+// UpdateHTTPCheck updates an HTTP check with the current client.
+func (r *ReloadingClient) UpdateHTTPCheck(check v1alpha1.HTTPCheck) error {
+	client, err := r.current()
+	if err != nil {
+		return err
+	}
+	return r.record(client, client.UpdateHTTPCheck(check))
+}
+
+// DeleteHTTPCheck deletes an HTTP check with the current client.
+func (r *ReloadingClient) DeleteHTTPCheck(check v1alpha1.HTTPCheck) error {
+	client, err := r.current()
+	if err != nil {
+		return err
+	}
+	return r.record(client, client.DeleteHTTPCheck(check))
+}
+
+// DeleteOrphanedHTTPChecks deletes the checks whose HTTPCheck no longer exists with the current
+// client.
+func (r *ReloadingClient) DeleteOrphanedHTTPChecks(list func() ([]v1alpha1.HTTPCheck, error)) error {
+	client, err := r.current()
+	if err != nil {
+		return err
+	}
+	return r.record(client, client.DeleteOrphanedHTTPChecks(list))
+}
+
+// UpdateContact updates a Pingdom contact with the current client.
+func (r *ReloadingClient) UpdateContact(contact v1alpha1.PingdomContact) error {
+	client, err := r.current()
+	if err != nil {
+		return err
+	}
+	return r.record(client, client.UpdateContact(contact))
+}
+
+// DeleteContact deletes a Pingdom contact with the current client.
+func (r *ReloadingClient) DeleteContact(contact v1alpha1.PingdomContact) error {
+	client, err := r.current()
+	if err != nil {
+		return err
+	}
+	return r.record(client, client.DeleteContact(contact))
+}
+
+// UpdateTeam updates a Pingdom team with the current client.
+func (r *ReloadingClient) UpdateTeam(team v1alpha1.PingdomTeam) error {
+	client, err := r.current()
+	if err != nil {
+		return err
+	}
+	return r.record(client, client.UpdateTeam(team))
+}
+
+// DeleteTeam deletes a Pingdom team with the current client.
+func (r *ReloadingClient) DeleteTeam(team v1alpha1.PingdomTeam) error {
+	client, err := r.current()
+	if err != nil {
+		return err
+	}
+	return r.record(client, client.DeleteTeam(team))
+}
+
+// UpdateMaintenanceWindow updates a Pingdom maintenance window with the current client.
+func (r *ReloadingClient) UpdateMaintenanceWindow(window v1alpha1.MaintenanceWindow, checks []v1alpha1.HTTPCheck) error {
+	client, err := r.current()
+	if err != nil {
+		return err
+	}
+	return r.record(client, client.UpdateMaintenanceWindow(window, checks))
+}
+
+// DeleteMaintenanceWindow deletes a Pingdom maintenance window with the current client.
+func (r *ReloadingClient) DeleteMaintenanceWindow(window v1alpha1.MaintenanceWindow) error {
+	client, err := r.current()
+	if err != nil {
+		return err
+	}
+	return r.record(client, client.DeleteMaintenanceWindow(window))
+}
+
+// CheckStates returns the state in Pingdom of the given checks with the current client.
+func (r *ReloadingClient) CheckStates(checks []v1alpha1.HTTPCheck) (map[string]CheckState, error) {
+	client, err := r.current()
+	if err != nil {
+		return nil, err
+	}
+	states, err := client.CheckStates(checks)
+	return states, r.record(client, err)
+}
+
+// Uptimes returns the uptime of a check over the given windows with the current client.
+func (r *ReloadingClient) Uptimes(check v1alpha1.HTTPCheck, windows []time.Duration) ([]Uptime, error) {
+	client, err := r.current()
+	if err != nil {
+		return nil, err
+	}
+	uptimes, err := client.Uptimes(check, windows)
+	return uptimes, r.record(client, err)
+}
+
+func (r *ReloadingClient) current() (checkClient, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.client == nil {
+		return nil, r.err
+	}
+	return r.client, nil
+}
+
+// record tracks whether the current client's credentials are rejected by Pingdom from the
+// outcome of a call made with the client, and returns the error of the call.
+func (r *ReloadingClient) record(client checkClient, err error) error {
+	rejected := IsUnauthorized(err)
+
+	r.Lock()
+	defer r.Unlock()
+	if client != r.client {
+		// The credentials were reloaded since the call was made.
+		return err
+	}
+	switch {
+	case rejected:
+		r.authErr = err
+	case err == nil, IsPermanent(err):
+		// Pingdom accepted the credentials, even if it rejected the call.
+		r.authErr = nil
+	}
+	return err
+}
+
+func (r *ReloadingClient) setErr(err error) {
+	r.Lock()
+	r.err = err
+	r.Unlock()
+}
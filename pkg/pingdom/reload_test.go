@@ -0,0 +1,161 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReloadingClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		check = v1alpha1.HTTPCheck{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+
+		first  = NewMockcheckClient(ctrl)
+		second = NewMockcheckClient(ctrl)
+
+		valid   = Credentials{Token: "first"}
+		rotated = Credentials{Token: "second"}
+		invalid = Credentials{Token: "invalid"}
+	)
+
+	var created int
+	client := newReloadingClient(func(creds Credentials) (checkClient, error) {
+		created++
+		switch creds {
+		case valid:
+			return first, nil
+		case rotated:
+			return second, nil
+		}
+		return nil, errors.New("unauthorized")
+	}, zap.NewNop())
+
+	assert.Error(t, client.Ready())
+	assert.Error(t, client.UpdateHTTPCheck(check))
+
+	require.NoError(t, client.Reload(valid))
+	require.NoError(t, client.Reload(valid))
+	assert.Equal(t, 1, created, "expected client to only be created when credentials change")
+	assert.NoError(t, client.Ready())
+
+	first.EXPECT().UpdateHTTPCheck(check).Return(nil).Times(2)
+	require.NoError(t, client.UpdateHTTPCheck(check))
+
+	// The current client is kept when the new credentials are invalid.
+	assert.Error(t, client.Reload(invalid))
+	assert.Error(t, client.Ready())
+	require.NoError(t, client.UpdateHTTPCheck(check))
+
+	require.NoError(t, client.Reload(rotated))
+	assert.NoError(t, client.Ready())
+
+	second.EXPECT().DeleteHTTPCheck(check).Return(nil)
+	require.NoError(t, client.DeleteHTTPCheck(check))
+}
+
+func TestReloadingClientWatch(t *testing.T) {
+	var (
+		loaded = make(chan Credentials)
+		stop   = make(chan struct{})
+		done   = make(chan struct{})
+	)
+
+	client := newReloadingClient(func(creds Credentials) (checkClient, error) {
+		loaded <- creds
+		return nil, errors.New("unauthorized")
+	}, zap.NewNop())
+
+	go func() {
+		client.Watch(stop, time.Millisecond, func() (Credentials, error) {
+			return Credentials{Token: "token"}, nil
+		})
+		close(done)
+	}()
+
+	assert.Equal(t, Credentials{Token: "token"}, <-loaded)
+	close(stop)
+
+	// Drain any reload which raced with stop.
+	for stopped := false; !stopped; {
+		select {
+		case <-loaded:
+		case <-done:
+			stopped = true
+		}
+	}
+	assert.Error(t, client.Ready())
+}
+
+func TestReloadingClientRejectedCredentials(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		check   = v1alpha1.HTTPCheck{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+		current = NewMockcheckClient(ctrl)
+		revoked = &AuthError{Op: "update check foo", Message: "Invalid application key"}
+	)
+
+	client := newReloadingClient(func(Credentials) (checkClient, error) {
+		return current, nil
+	}, zap.NewNop())
+	require.NoError(t, client.Reload(Credentials{Token: "token"}))
+
+	gomock.InOrder(
+		current.EXPECT().UpdateHTTPCheck(check).Return(revoked),
+		current.EXPECT().CheckStates(nil).Return(nil, &NotFoundError{Op: "list checks"}),
+		current.EXPECT().UpdateHTTPCheck(check).Return(revoked),
+		current.EXPECT().UpdateHTTPCheck(check).Return(&TransientError{Op: "update check foo"}),
+		current.EXPECT().UpdateHTTPCheck(check).Return(nil),
+	)
+
+	// The credentials are rejected although they haven't changed.
+	assert.Equal(t, revoked, client.UpdateHTTPCheck(check))
+	assert.Equal(t, revoked, client.Ready())
+
+	// Any answer from Pingdom other than rejecting the credentials means they're accepted.
+	_, err := client.CheckStates(nil)
+	assert.Error(t, err)
+	assert.NoError(t, client.Ready())
+
+	assert.Error(t, client.UpdateHTTPCheck(check))
+	assert.Error(t, client.Ready())
+
+	// Failing to reach Pingdom doesn't tell whether the credentials are accepted.
+	assert.Error(t, client.UpdateHTTPCheck(check))
+	assert.Error(t, client.Ready())
+
+	require.NoError(t, client.UpdateHTTPCheck(check))
+	assert.NoError(t, client.Ready())
+}
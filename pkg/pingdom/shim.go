@@ -20,7 +20,12 @@
 
 package pingdom
 
-import "github.com/russellcardullo/go-pingdom/pingdom"
+import (
+	"strconv"
+	"time"
+
+	"github.com/russellcardullo/go-pingdom/pingdom"
+)
 
 type shimClient struct {
 	client *pingdom.Client
@@ -30,5 +35,125 @@ func newShimClient(client *pingdom.Client) *shimClient {
 	return &shimClient{client: client}
 }
 
-func (c *shimClient) Users() userService   { return c.client.Users }
-func (c *shimClient) Checks() checkService { return c.client.Checks }
+func (c *shimClient) Users() userService               { return c.client.Users }
+func (c *shimClient) Checks() checkService             { return c.client.Checks }
+func (c *shimClient) Teams() teamService               { return c.client.Teams }
+func (c *shimClient) Maintenances() maintenanceService { return c.client.Maintenances }
+func (c *shimClient) Summaries() summaryService        { return summaryShim{client: c.client} }
+func (c *shimClient) Alerting() alertingService        { return alertingShim{client: c.client} }
+
+// summaryStatus is the total time a check spent in each state during a period, in seconds.
+type summaryStatus struct {
+	TotalUp      int64 `json:"totalup"`
+	TotalDown    int64 `json:"totaldown"`
+	TotalUnknown int64 `json:"totalunknown"`
+}
+
+// summaryShim reads summaries with the requests of the Pingdom client.
+type summaryShim struct {
+	client *pingdom.Client
+}
+
+// Average reads the summary.average of a check, including the time it spent in each state.
+func (s summaryShim) Average(checkID int, from, to time.Time) (*summaryStatus, error) {
+	req, err := s.client.NewRequest("GET", "/summary.average/"+strconv.Itoa(checkID), map[string]string{
+		"from":          strconv.FormatInt(from.Unix(), 10),
+		"to":            strconv.FormatInt(to.Unix(), 10),
+		"includeuptime": "true",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Summary struct {
+			Status summaryStatus `json:"status"`
+		} `json:"summary"`
+	}
+	if _, err := s.client.Do(req, &res); err != nil {
+		return nil, err
+	}
+	return &res.Summary.Status, nil
+}
+
+// summaryState is an interval during which a check was in a state, as Unix timestamps.
+type summaryState struct {
+	Status   string `json:"status"`
+	TimeFrom int64  `json:"timefrom"`
+	TimeTo   int64  `json:"timeto"`
+}
+
+// Outages reads the summary.outage of a check, the intervals it spent in each state.
+func (s summaryShim) Outages(checkID int, from, to time.Time) ([]summaryState, error) {
+	req, err := s.client.NewRequest("GET", "/summary.outage/"+strconv.Itoa(checkID), map[string]string{
+		"from": strconv.FormatInt(from.Unix(), 10),
+		"to":   strconv.FormatInt(to.Unix(), 10),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Summary struct {
+			States []summaryState `json:"states"`
+		} `json:"summary"`
+	}
+	if _, err := s.client.Do(req, &res); err != nil {
+		return nil, err
+	}
+	return res.Summary.States, nil
+}
+
+// alertingContact is a contact of the alerting API, with the email addresses it's alerted at.
+type alertingContact struct {
+	ID                  int    `json:"id"`
+	Name                string `json:"name"`
+	NotificationTargets struct {
+		Email []struct {
+			Address string `json:"address"`
+		} `json:"email"`
+	} `json:"notification_targets"`
+}
+
+// alertingTeam is a team of the alerting API.
+type alertingTeam struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// alertingShim lists contacts and teams with the requests of the Pingdom client.
+type alertingShim struct {
+	client *pingdom.Client
+}
+
+// Contacts lists the contacts of the account.
+func (s alertingShim) Contacts() ([]alertingContact, error) {
+	req, err := s.client.NewRequest("GET", "/alerting/contacts", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Contacts []alertingContact `json:"contacts"`
+	}
+	if _, err := s.client.Do(req, &res); err != nil {
+		return nil, err
+	}
+	return res.Contacts, nil
+}
+
+// Teams lists the teams of the account.
+func (s alertingShim) Teams() ([]alertingTeam, error) {
+	req, err := s.client.NewRequest("GET", "/alerting/teams", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Teams []alertingTeam `json:"teams"`
+	}
+	if _, err := s.client.Do(req, &res); err != nil {
+		return nil, err
+	}
+	return res.Teams, nil
+}
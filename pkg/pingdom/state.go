@@ -0,0 +1,81 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/russellcardullo/go-pingdom/pingdom"
+)
+
+// CheckState is the state of a check in Pingdom.
+type CheckState struct {
+	// Status is one of up, down, unconfirmed_down, unknown or paused.
+	Status string
+
+	// LastTestTime and LastErrorTime are zero if the check hasn't been tested or failed.
+	LastTestTime     time.Time
+	LastErrorTime    time.Time
+	LastResponseTime time.Duration
+}
+
+// CheckStates returns the state in Pingdom of those of the given checks which exist, keyed by
+// their namespace and name. The states of all checks are read from the list of checks.
+func (c *Client) CheckStates(checks []v1alpha1.HTTPCheck) (map[string]CheckState, error) {
+	list, err := c.listChecks(map[string]string{
+		"tags":         heimdallrTag,
+		"include_tags": "true",
+	})
+	if err != nil {
+		return nil, classify("get current list of heimdallr checks", err)
+	}
+
+	owned := make(map[string]CheckState)
+	for _, cr := range list {
+		if c.isOwned(cr) {
+			owned[checkOwner(cr)] = toCheckState(cr)
+		}
+	}
+
+	states := make(map[string]CheckState)
+	for _, check := range checks {
+		if state, ok := owned[c.ownerTag(check)]; ok {
+			states[getName(check)] = state
+		}
+	}
+	return states, nil
+}
+
+func toCheckState(cr pingdom.CheckResponse) CheckState {
+	state := CheckState{
+		Status:           cr.Status,
+		LastResponseTime: time.Duration(cr.LastResponseTime) * time.Millisecond,
+	}
+	if cr.LastTestTime != 0 {
+		state.LastTestTime = time.Unix(cr.LastTestTime, 0)
+	}
+	if cr.LastErrorTime != 0 {
+		state.LastErrorTime = time.Unix(cr.LastErrorTime, 0)
+	}
+	return state
+}
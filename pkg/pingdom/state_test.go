@@ -0,0 +1,113 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/golang/mock/gomock"
+	"github.com/russellcardullo/go-pingdom/pingdom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckStates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+		client = &Client{client: cli, logger: zap.NewNop()}
+
+		foo = v1alpha1.HTTPCheck{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "foo"}}
+		bar = v1alpha1.HTTPCheck{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "bar"}}
+	)
+
+	checks.EXPECT().List(map[string]string{
+		"tags":         heimdallrTag,
+		"include_tags": "true",
+		"limit":        "25000",
+		"offset":       "0",
+	}).Return([]pingdom.CheckResponse{
+		{
+			Name:             "web/foo",
+			Status:           "down",
+			LastTestTime:     1538431200,
+			LastErrorTime:    1538431140,
+			LastResponseTime: 250,
+			Tags: []pingdom.CheckResponseTag{
+				{Name: heimdallrTag},
+				{Name: client.ownerTag(foo)},
+			},
+		},
+		{
+			// Checks which haven't been tested yet have no times.
+			Name:   "web/bar",
+			Status: "unknown",
+			Tags: []pingdom.CheckResponseTag{
+				{Name: heimdallrTag},
+				{Name: client.ownerTag(bar)},
+			},
+		},
+		{
+			Name:   "web/other",
+			Status: "up",
+			Tags:   []pingdom.CheckResponseTag{{Name: heimdallrTag}},
+		},
+	}, nil)
+	cli.EXPECT().Checks().Return(checks)
+
+	states, err := client.CheckStates([]v1alpha1.HTTPCheck{foo, bar})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]CheckState{
+		"web/foo": {
+			Status:           "down",
+			LastTestTime:     time.Unix(1538431200, 0),
+			LastErrorTime:    time.Unix(1538431140, 0),
+			LastResponseTime: 250 * time.Millisecond,
+		},
+		"web/bar": {Status: "unknown"},
+	}, states)
+}
+
+func TestCheckStatesUnauthorized(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		checks = NewMockcheckService(ctrl)
+		cli    = NewMockpingdomClient(ctrl)
+		client = &Client{client: cli, logger: zap.NewNop()}
+	)
+
+	checks.EXPECT().List(gomock.Any()).Return(nil, &pingdom.PingdomError{StatusCode: 401, Message: "Invalid token"})
+	cli.EXPECT().Checks().Return(checks)
+
+	// The error stays typed, so that rejected credentials are noticed by the poller too.
+	_, err := client.CheckStates(nil)
+	assert.True(t, IsUnauthorized(err))
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/russellcardullo/go-pingdom/pingdom"
+)
+
+// maxTagLength is the maximum length of a Pingdom tag.
+const maxTagLength = 64
+
+// WithLabelTags configures which labels of a check are added to it as Pingdom tags. Labels whose
+// key starts with the prefix are added without the prefix, and labels whose key is one of the
+// given keys are added as is. A label is added as a tag of the form <key>_<value>.
+func WithLabelTags(prefix string, keys ...string) Option {
+	return func(c *Client) {
+		c.labelPrefix = prefix
+		c.labelKeys = make(map[string]bool, len(keys))
+		for _, key := range keys {
+			c.labelKeys[key] = true
+		}
+	}
+}
+
+// tags returns the tags of the Pingdom check with the given owner tag.
+func (c *Client) tags(key string, check v1alpha1.HTTPCheck) string {
+	tags := []string{heimdallrTag, key}
+	if c.cluster != "" {
+		tags = append(tags, clusterTagPrefix+c.cluster)
+	}
+	tags = append(tags, c.userTags(check)...)
+	return strings.Join(tags, ",")
+}
+
+// userTags returns the tags of the Pingdom check derived from the labels and tags of the given
+// check, sorted and without duplicates.
+func (c *Client) userTags(check v1alpha1.HTTPCheck) []string {
+	var (
+		tags []string
+		seen = make(map[string]bool)
+	)
+	add := func(tag string) {
+		tag = sanitizeTag(tag)
+		if tag == "" || seen[tag] || isOwnershipTag(tag) {
+			return
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+
+	for key, value := range check.Labels {
+		switch {
+		case c.labelPrefix != "" && strings.HasPrefix(key, c.labelPrefix):
+			key = strings.TrimPrefix(key, c.labelPrefix)
+		case c.labelKeys[key]:
+		default:
+			continue
+		}
+
+		if value == "" {
+			add(key)
+		} else {
+			add(key + "_" + value)
+		}
+	}
+	for _, tag := range check.Spec.Tags {
+		add(tag)
+	}
+
+	sort.Strings(tags)
+	return tags
+}
+
+// readUserTags returns the tags of a Pingdom check which weren't added by heimdallr to identify
+// it.
+func readUserTags(cr *pingdom.CheckResponse) []string {
+	var tags []string
+	for _, tag := range cr.Tags {
+		if !isOwnershipTag(tag.Name) {
+			tags = append(tags, tag.Name)
+		}
+	}
+	return tags
+}
+
+// isOwnershipTag returns whether a tag is used by heimdallr to identify the checks it manages.
+func isOwnershipTag(tag string) bool {
+	return tag == heimdallrTag ||
+		strings.HasPrefix(tag, ownerTagPrefix) ||
+		strings.HasPrefix(tag, clusterTagPrefix)
+}
+
+// sanitizeTag returns the tag lowercased, with any characters Pingdom doesn't allow in tags
+// replaced by dashes and truncated to the maximum length of a tag.
+func sanitizeTag(tag string) string {
+	tag = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '-'
+		}
+	}, tag)
+	if len(tag) > maxTagLength {
+		tag = tag[:maxTagLength]
+	}
+	return strings.Trim(tag, "-_")
+}
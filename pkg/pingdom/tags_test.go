@@ -0,0 +1,76 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/russellcardullo/go-pingdom/pingdom"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUserTags(t *testing.T) {
+	check := v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "foo",
+			Labels: map[string]string{
+				"pingdom.heimdallr.io/team": "Payments",
+				"pingdom.heimdallr.io/beta": "",
+				"app":                       "checkout",
+				"environment":               "production",
+			},
+		},
+		Spec: v1alpha1.HTTPCheckSpec{
+			Tags: []string{"service:checkout", "team_payments", heimdallrTag, ""},
+		},
+	}
+
+	var client Client
+	assert.Equal(t, []string{"service-checkout", "team_payments"}, client.userTags(check))
+
+	WithLabelTags("pingdom.heimdallr.io/", "app")(&client)
+	assert.Equal(t, []string{"app_checkout", "beta", "service-checkout", "team_payments"},
+		client.userTags(check))
+	assert.Equal(t, heimdallrTag+",heimdallr-id-1,app_checkout,beta,service-checkout,team_payments",
+		client.tags("heimdallr-id-1", check))
+}
+
+func TestSanitizeTag(t *testing.T) {
+	assert.Equal(t, "team_payments", sanitizeTag("Team_Payments"))
+	assert.Equal(t, "example-com-path", sanitizeTag("/example.com/path/"))
+	assert.Len(t, sanitizeTag(strings.Repeat("a", 100)), maxTagLength)
+}
+
+func TestReadUserTags(t *testing.T) {
+	cr := &pingdom.CheckResponse{
+		Tags: []pingdom.CheckResponseTag{
+			{Name: heimdallrTag},
+			{Name: "heimdallr-id-1"},
+			{Name: "heimdallr-cluster-east"},
+			{Name: "team_payments"},
+		},
+	}
+	assert.Equal(t, []string{"team_payments"}, readUserTags(cr))
+}
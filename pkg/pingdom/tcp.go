@@ -0,0 +1,90 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tcpCheck is a Pingdom check which only opens a TCP connection to a port. It implements the
+// pingdom.Check interface, with the parameters of the TCP checks of the Pingdom API.
+type tcpCheck struct {
+	Name                     string
+	Hostname                 string
+	Port                     int
+	Resolution               int
+	Paused                   bool
+	SendNotificationWhenDown int
+	NotifyAgainEvery         int
+	NotifyWhenBackup         bool
+	IntegrationIds           []int
+	Tags                     string
+	UserIds                  []int
+	TeamIds                  []int
+}
+
+// PutParams returns the parameters of a request updating the check.
+func (ck *tcpCheck) PutParams() map[string]string {
+	return map[string]string{
+		"name":                     ck.Name,
+		"host":                     ck.Hostname,
+		"port":                     strconv.Itoa(ck.Port),
+		"resolution":               strconv.Itoa(ck.Resolution),
+		"paused":                   strconv.FormatBool(ck.Paused),
+		"sendnotificationwhendown": strconv.Itoa(ck.SendNotificationWhenDown),
+		"notifyagainevery":         strconv.Itoa(ck.NotifyAgainEvery),
+		"notifywhenbackup":         strconv.FormatBool(ck.NotifyWhenBackup),
+		"integrationids":           joinIDs(ck.IntegrationIds),
+		"tags":                     ck.Tags,
+		"userids":                  joinIDs(ck.UserIds),
+		"teamids":                  joinIDs(ck.TeamIds),
+	}
+}
+
+// PostParams returns the parameters of a request creating the check.
+func (ck *tcpCheck) PostParams() map[string]string {
+	params := ck.PutParams()
+	params["type"] = "tcp"
+	return params
+}
+
+// Valid returns an error if the check can't be created.
+func (ck *tcpCheck) Valid() error {
+	switch {
+	case ck.Name == "":
+		return fmt.Errorf("Invalid value for `Name`.  Must contain non-empty string")
+	case ck.Hostname == "":
+		return fmt.Errorf("Invalid value for `Hostname`.  Must contain non-empty string")
+	case ck.Port <= 0 || ck.Port > 65535:
+		return fmt.Errorf("Invalid value %v for `Port`.  Must be between 1 and 65535", ck.Port)
+	}
+	return nil
+}
+
+func joinIDs(ids []int) string {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = strconv.Itoa(id)
+	}
+	return strings.Join(strs, ",")
+}
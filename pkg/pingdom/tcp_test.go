@@ -0,0 +1,70 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTCPCheckParams(t *testing.T) {
+	check := tcpCheck{
+		Name:                     "other/postgres",
+		Hostname:                 "10.0.0.1",
+		Port:                     5432,
+		Resolution:               5,
+		SendNotificationWhenDown: 2,
+		NotifyWhenBackup:         true,
+		IntegrationIds:           []int{1, 2},
+		Tags:                     "heimdallr",
+		UserIds:                  []int{3},
+	}
+
+	expected := map[string]string{
+		"name":                     "other/postgres",
+		"host":                     "10.0.0.1",
+		"port":                     "5432",
+		"resolution":               "5",
+		"paused":                   "false",
+		"sendnotificationwhendown": "2",
+		"notifyagainevery":         "0",
+		"notifywhenbackup":         "true",
+		"integrationids":           "1,2",
+		"tags":                     "heimdallr",
+		"userids":                  "3",
+		"teamids":                  "",
+	}
+	assert.Equal(t, expected, check.PutParams())
+
+	expected["type"] = "tcp"
+	assert.Equal(t, expected, check.PostParams())
+}
+
+func TestTCPCheckValid(t *testing.T) {
+	check := tcpCheck{Name: "other/postgres", Hostname: "10.0.0.1"}
+
+	err := check.Valid()
+	assert.Equal(t, &ValidationError{Op: "create check", Field: "port", Message: err.Error()}, classify("create check", err))
+
+	check.Port = 5432
+	assert.NoError(t, check.Valid())
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import "net/http"
+
+// apiBaseURL is the base URL of the version of the Pingdom API which supports token authentication.
+const apiBaseURL = "https://api.pingdom.com/api/3.1"
+
+// legacyBaseURL is the base URL of the version of the Pingdom API which supports basic auth and
+// application keys.
+const legacyBaseURL = "https://api.pingdom.com/api/2.1"
+
+// bearerTransport authenticates requests with a Pingdom API token, replacing the basic auth and
+// application key headers set by the legacy client.
+type bearerTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func newBearerTransport(token string, base http.RoundTripper) *bearerTransport {
+	return &bearerTransport{token: token, base: base}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the original request.
+	r := req.WithContext(req.Context())
+	r.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		r.Header[k] = append([]string(nil), v...)
+	}
+
+	r.Header.Set("Authorization", "Bearer "+t.token)
+	r.Header.Del("App-Key")
+
+	return t.base.RoundTrip(r)
+}
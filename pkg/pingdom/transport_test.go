@@ -0,0 +1,137 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeAPI is a fake Pingdom 3.1 API which only accepts requests with the given token.
+type fakeAPI struct {
+	t       *testing.T
+	token   string
+	created []string
+	userIDs []string
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+f.token || r.Header.Get("App-Key") != "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		f.write(w, map[string]interface{}{
+			"error": map[string]interface{}{"code": http.StatusUnauthorized, "errormessage": "invalid token"},
+		})
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/3.1/checks":
+		assert.Equal(f.t, heimdallrTag, r.URL.Query().Get("tags"))
+		f.write(w, map[string]interface{}{"checks": []interface{}{}})
+	case r.Method == http.MethodPost && r.URL.Path == "/api/3.1/checks":
+		f.created = append(f.created, r.FormValue("name"))
+		f.userIDs = append(f.userIDs, r.FormValue("userids"))
+		f.write(w, map[string]interface{}{"check": map[string]interface{}{"id": 42, "name": r.FormValue("name")}})
+	case r.Method == http.MethodGet && r.URL.Path == "/api/3.1/alerting/contacts":
+		f.write(w, map[string]interface{}{"contacts": []interface{}{
+			map[string]interface{}{
+				"id":   11,
+				"name": "Alice",
+				"notification_targets": map[string]interface{}{
+					"email": []interface{}{map[string]interface{}{"address": "alice@example.com"}},
+				},
+			},
+		}})
+	case r.Method == http.MethodGet && r.URL.Path == "/api/3.1/alerting/teams":
+		f.write(w, map[string]interface{}{"teams": []interface{}{
+			map[string]interface{}{"id": 12, "name": "payments"},
+		}})
+	default:
+		f.t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeAPI) write(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	require.NoError(f.t, json.NewEncoder(w).Encode(v))
+}
+
+func TestNewWithToken(t *testing.T) {
+	api := &fakeAPI{t: t, token: "secret"}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client, err := newWithToken(server.URL+"/api/3.1", "secret", zap.NewNop(), WithContactIDs(7))
+	require.NoError(t, err)
+
+	err = client.UpdateHTTPCheck(v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"},
+		Spec:       v1alpha1.HTTPCheckSpec{Hostname: "foo.io"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"default/foo"}, api.created)
+	assert.Equal(t, []string{"7"}, api.userIDs)
+	assert.Equal(t, 42, client.httpChecks[ownerTagFromName("default/foo")].id)
+}
+
+func TestNewWithTokenResolvesAlertingContacts(t *testing.T) {
+	api := &fakeAPI{t: t, token: "secret"}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client, err := newWithToken(server.URL+"/api/3.1", "secret", zap.NewNop())
+	require.NoError(t, err)
+
+	// Token authenticated clients look up contacts and teams with the alerting API, since the
+	// users and teams of the 2.1 API aren't available to them.
+	_, rcpts, err := client.resolve("default", v1alpha1.HTTPCheckSpec{
+		Contacts: []string{"alice@example.com"},
+		Teams:    []string{"payments"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, recipients{userIDs: []int{11}, teamIDs: []int{12}}, rcpts)
+
+	err = client.UpdateHTTPCheck(v1alpha1.HTTPCheck{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"},
+		Spec:       v1alpha1.HTTPCheckSpec{Hostname: "foo.io", Contacts: []string{"Alice"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"11"}, api.userIDs)
+}
+
+func TestNewWithTokenUnauthorized(t *testing.T) {
+	server := httptest.NewServer(&fakeAPI{t: t, token: "secret"})
+	defer server.Close()
+
+	_, err := newWithToken(server.URL+"/api/3.1", "wrong", zap.NewNop())
+	assert.Error(t, err)
+}
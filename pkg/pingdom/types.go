@@ -20,12 +20,23 @@
 
 package pingdom
 
-import "github.com/russellcardullo/go-pingdom/pingdom"
+import (
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/russellcardullo/go-pingdom/pingdom"
+)
 
 //go:generate mockgen -source types.go -destination mocks.go -package pingdom
 
 type userService interface {
 	List() ([]pingdom.UsersResponse, error)
+	Create(user pingdom.User) (*pingdom.UsersResponse, error)
+	Update(id int, user pingdom.User) (*pingdom.PingdomResponse, error)
+	Delete(id int) (*pingdom.PingdomResponse, error)
+	CreateContact(userID int, contact pingdom.Contact) (*pingdom.CreateUserContactResponse, error)
+	DeleteContact(userID int, contactID int) (*pingdom.PingdomResponse, error)
 }
 
 type checkService interface {
@@ -36,7 +47,53 @@ type checkService interface {
 	List(params ...map[string]string) ([]pingdom.CheckResponse, error)
 }
 
+type teamService interface {
+	List() ([]pingdom.TeamResponse, error)
+	Create(team pingdom.TeamData) (*pingdom.TeamResponse, error)
+	Update(id int, team pingdom.TeamData) (*pingdom.TeamResponse, error)
+	Delete(id int) (*pingdom.TeamDeleteResponse, error)
+}
+
+type maintenanceService interface {
+	List(params ...map[string]string) ([]pingdom.MaintenanceResponse, error)
+	Create(maintenance pingdom.Maintenance) (*pingdom.MaintenanceResponse, error)
+	Update(id int, maintenance pingdom.Maintenance) (*pingdom.PingdomResponse, error)
+	Delete(id int) (*pingdom.PingdomResponse, error)
+}
+
+// summaryService reads summaries of the results of checks, which the Pingdom client doesn't
+// support.
+type summaryService interface {
+	Average(checkID int, from, to time.Time) (*summaryStatus, error)
+	Outages(checkID int, from, to time.Time) ([]summaryState, error)
+}
+
+// alertingService lists the contacts and teams of the alerting API of version 3.1, which replaced
+// the users and teams of version 2.1 and which the Pingdom client doesn't support.
+type alertingService interface {
+	Contacts() ([]alertingContact, error)
+	Teams() ([]alertingTeam, error)
+}
+
 type pingdomClient interface {
 	Users() userService
 	Checks() checkService
+	Teams() teamService
+	Maintenances() maintenanceService
+	Summaries() summaryService
+	Alerting() alertingService
+}
+
+type checkClient interface {
+	UpdateHTTPCheck(check v1alpha1.HTTPCheck) error
+	DeleteHTTPCheck(check v1alpha1.HTTPCheck) error
+	DeleteOrphanedHTTPChecks(list func() ([]v1alpha1.HTTPCheck, error)) error
+	UpdateContact(contact v1alpha1.PingdomContact) error
+	DeleteContact(contact v1alpha1.PingdomContact) error
+	UpdateTeam(team v1alpha1.PingdomTeam) error
+	DeleteTeam(team v1alpha1.PingdomTeam) error
+	UpdateMaintenanceWindow(window v1alpha1.MaintenanceWindow, checks []v1alpha1.HTTPCheck) error
+	DeleteMaintenanceWindow(window v1alpha1.MaintenanceWindow) error
+	CheckStates(checks []v1alpha1.HTTPCheck) (map[string]CheckState, error)
+	Uptimes(check v1alpha1.HTTPCheck, windows []time.Duration) ([]Uptime, error)
 }
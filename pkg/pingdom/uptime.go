@@ -0,0 +1,196 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+)
+
+// defaultUptimeRefresh is how long the uptime of a check is cached for by default. Each window
+// of each check takes a request, so uptimes are cached to stay within Pingdom's rate limits.
+const defaultUptimeRefresh = time.Hour
+
+// Uptime is the percentage of a window, ending now, for which a check was up.
+type Uptime struct {
+	Window  time.Duration
+	Percent float64
+}
+
+// WithUptimeRefresh configures how long the uptime of a check is cached for before it's read
+// from Pingdom again.
+func WithUptimeRefresh(refresh time.Duration) Option {
+	return func(c *Client) {
+		c.uptimes.refresh = refresh
+	}
+}
+
+// Uptimes returns the uptime of a check over each of the given windows. Windows in which the
+// check was neither up nor down, such as those before it was created, are omitted. Checks which
+// don't exist in Pingdom yet have no uptime.
+func (c *Client) Uptimes(check v1alpha1.HTTPCheck, windows []time.Duration) ([]Uptime, error) {
+	hc, ok := c.lookup(c.ownerTag(check))
+	if !ok || hc.id == 0 {
+		return nil, nil
+	}
+
+	var uptimes []Uptime
+	for _, window := range windows {
+		percent, ok, err := c.uptimes.get(c.client, hc.id, window)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get uptime of check over %v: %v", window, err)
+		}
+		if ok {
+			uptimes = append(uptimes, Uptime{Window: window, Percent: percent})
+		}
+	}
+	return uptimes, nil
+}
+
+type uptimeKey struct {
+	id     int
+	window time.Duration
+}
+
+type cachedUptime struct {
+	percent float64
+	ok      bool
+	fetched time.Time
+}
+
+// uptimeCache caches the uptimes of checks read from Pingdom.
+type uptimeCache struct {
+	mu      sync.Mutex
+	entries map[uptimeKey]cachedUptime
+	refresh time.Duration
+	now     func() time.Time
+}
+
+// get returns the uptime of a check over a window, reading it from Pingdom if it isn't cached or
+// was cached more than the refresh interval ago. The cache isn't locked while reading, so that a
+// slow request doesn't block the uptimes of other checks.
+func (u *uptimeCache) get(client pingdomClient, id int, window time.Duration) (float64, bool, error) {
+	now := u.clock()
+	key := uptimeKey{id: id, window: window}
+	if cached, ok := u.lookup(key, now); ok {
+		return cached.percent, cached.ok, nil
+	}
+
+	cached, err := readUptime(client, id, now.Add(-window), now)
+	if err != nil {
+		return 0, false, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.entries == nil {
+		u.entries = make(map[uptimeKey]cachedUptime)
+	}
+	u.entries[key] = cached
+	return cached.percent, cached.ok, nil
+}
+
+// lookup returns the cached uptime of a check over a window if it was cached less than the
+// refresh interval ago.
+func (u *uptimeCache) lookup(key uptimeKey, now time.Time) (cachedUptime, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	cached, ok := u.entries[key]
+	if !ok || now.Sub(cached.fetched) >= u.refreshInterval() {
+		return cachedUptime{}, false
+	}
+	return cached, true
+}
+
+// readUptime reads the uptime of a check between two times from the totalup and totaldown of the
+// status summary.average returns with includeuptime, the seconds the check spent up and down.
+// Pingdom's documentation doesn't guarantee the totals are set for every check, so if neither is
+// the intervals summary.outage lists are summed instead, clipped to the window, rather than
+// reporting a window with downtime as having no results.
+func readUptime(client pingdomClient, id int, from, to time.Time) (cachedUptime, error) {
+	status, err := client.Summaries().Average(id, from, to)
+	if err != nil {
+		return cachedUptime{}, err
+	}
+
+	up, down := status.TotalUp, status.TotalDown
+	if up+down == 0 {
+		states, err := client.Summaries().Outages(id, from, to)
+		if err != nil {
+			return cachedUptime{}, err
+		}
+		for _, state := range states {
+			start, end := state.TimeFrom, state.TimeTo
+			if start < from.Unix() {
+				start = from.Unix()
+			}
+			if end > to.Unix() {
+				end = to.Unix()
+			}
+			if end <= start {
+				continue
+			}
+
+			switch state.Status {
+			case "up":
+				up += end - start
+			case "down":
+				down += end - start
+			}
+		}
+	}
+
+	cached := cachedUptime{fetched: to}
+	if total := up + down; total > 0 {
+		cached.percent = 100 * float64(up) / float64(total)
+		cached.ok = true
+	}
+	return cached, nil
+}
+
+// forget removes the cached uptimes of a check.
+func (u *uptimeCache) forget(id int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for key := range u.entries {
+		if key.id == id {
+			delete(u.entries, key)
+		}
+	}
+}
+
+func (u *uptimeCache) refreshInterval() time.Duration {
+	if u.refresh > 0 {
+		return u.refresh
+	}
+	return defaultUptimeRefresh
+}
+
+func (u *uptimeCache) clock() time.Time {
+	if u.now != nil {
+		return u.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2018 Jerome Froelich
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pingdom
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jeromefroe/heimdallr/pkg/apis/heimdallr/v1alpha1"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUptimes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		now       = time.Date(2018, time.October, 1, 12, 0, 0, 0, time.UTC)
+		summaries = NewMocksummaryService(ctrl)
+		cli       = NewMockpingdomClient(ctrl)
+		client    = &Client{client: cli, logger: zap.NewNop(), httpChecks: make(map[string]httpCheck)}
+
+		check   = v1alpha1.HTTPCheck{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "foo"}}
+		day     = 24 * time.Hour
+		week    = 7 * day
+		windows = []time.Duration{day, week}
+	)
+	client.uptimes.now = func() time.Time { return now }
+	client.store(client.ownerTag(check), httpCheck{id: 42})
+
+	cli.EXPECT().Summaries().AnyTimes().Return(summaries)
+	summaries.EXPECT().Average(42, now.Add(-day), now).Return(&summaryStatus{TotalUp: 86310, TotalDown: 90}, nil)
+
+	// The check didn't exist a week ago, but the time before it was created is unknown rather
+	// than down.
+	summaries.EXPECT().Average(42, now.Add(-week), now).Return(&summaryStatus{
+		TotalUp:      86310,
+		TotalDown:    90,
+		TotalUnknown: 6 * 86400,
+	}, nil)
+
+	uptimes, err := client.Uptimes(check, windows)
+	require.NoError(t, err)
+	assert.Equal(t, []Uptime{{Window: day, Percent: 99.895833333333333}, {Window: week, Percent: 99.895833333333333}}, uptimes)
+
+	// Uptimes are cached until the refresh interval passes.
+	now = now.Add(30 * time.Minute)
+	_, err = client.Uptimes(check, windows)
+	require.NoError(t, err)
+
+	now = now.Add(30 * time.Minute)
+	summaries.EXPECT().Average(42, now.Add(-day), now).Return(&summaryStatus{TotalUp: 86400}, nil)
+	summaries.EXPECT().Average(42, now.Add(-week), now).Return(&summaryStatus{TotalUnknown: 7 * 86400}, nil)
+	summaries.EXPECT().Outages(42, now.Add(-week), now).Return([]summaryState{
+		{Status: "unknown", TimeFrom: now.Add(-week).Unix(), TimeTo: now.Unix()},
+	}, nil)
+
+	// A window without any results is omitted.
+	uptimes, err = client.Uptimes(check, windows)
+	require.NoError(t, err)
+	assert.Equal(t, []Uptime{{Window: day, Percent: 100}}, uptimes)
+}
+
+func TestUptimesFromOutages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		now       = time.Date(2018, time.October, 1, 12, 0, 0, 0, time.UTC)
+		from      = now.Add(-time.Hour)
+		summaries = NewMocksummaryService(ctrl)
+		cli       = NewMockpingdomClient(ctrl)
+		client    = &Client{client: cli, logger: zap.NewNop(), httpChecks: make(map[string]httpCheck)}
+		check     = v1alpha1.HTTPCheck{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "foo"}}
+	)
+	client.uptimes.now = func() time.Time { return now }
+	client.store(client.ownerTag(check), httpCheck{id: 42})
+
+	// Without totals the intervals of each state are summed, clipped to the window.
+	cli.EXPECT().Summaries().Times(2).Return(summaries)
+	summaries.EXPECT().Average(42, from, now).Return(&summaryStatus{}, nil)
+	summaries.EXPECT().Outages(42, from, now).Return([]summaryState{
+		{Status: "up", TimeFrom: from.Add(-time.Hour).Unix(), TimeTo: from.Add(45 * time.Minute).Unix()},
+		{Status: "down", TimeFrom: from.Add(45 * time.Minute).Unix(), TimeTo: now.Unix()},
+	}, nil)
+
+	uptimes, err := client.Uptimes(check, []time.Duration{time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, []Uptime{{Window: time.Hour, Percent: 75}}, uptimes)
+}
+
+func TestUptimesUnlockedDuringRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		now       = time.Now()
+		summaries = NewMocksummaryService(ctrl)
+		cli       = NewMockpingdomClient(ctrl)
+		cache     = uptimeCache{now: func() time.Time { return now }}
+		started   = make(chan struct{})
+		release   = make(chan struct{})
+	)
+	cache.entries = map[uptimeKey]cachedUptime{
+		{id: 1, window: time.Hour}: {percent: 100, ok: true, fetched: now},
+	}
+
+	cli.EXPECT().Summaries().Return(summaries)
+	summaries.EXPECT().Average(2, gomock.Any(), gomock.Any()).DoAndReturn(func(int, time.Time, time.Time) (*summaryStatus, error) {
+		close(started)
+		<-release
+		return &summaryStatus{TotalUp: 60}, nil
+	})
+
+	done := make(chan error)
+	go func() {
+		_, _, err := cache.get(cli, 2, time.Hour)
+		done <- err
+	}()
+	<-started
+
+	// Cached uptimes are returned while another is being read.
+	percent, ok, err := cache.get(cli, 1, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, float64(100), percent)
+
+	close(release)
+	require.NoError(t, <-done)
+	assert.Equal(t, cachedUptime{percent: 100, ok: true, fetched: now}, cache.entries[uptimeKey{id: 2, window: time.Hour}])
+}
+
+func TestUptimesError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		summaries = NewMocksummaryService(ctrl)
+		cli       = NewMockpingdomClient(ctrl)
+		client    = &Client{client: cli, logger: zap.NewNop(), httpChecks: make(map[string]httpCheck)}
+
+		check   = v1alpha1.HTTPCheck{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "foo"}}
+		created = v1alpha1.HTTPCheck{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "bar"}}
+	)
+	client.store(client.ownerTag(created), httpCheck{id: 42})
+
+	// Checks which don't exist in Pingdom have no uptime.
+	uptimes, err := client.Uptimes(check, []time.Duration{time.Hour})
+	require.NoError(t, err)
+	assert.Empty(t, uptimes)
+
+	cli.EXPECT().Summaries().Return(summaries)
+	summaries.EXPECT().Average(42, gomock.Any(), gomock.Any()).Return(nil, errors.New("rate limited"))
+
+	_, err = client.Uptimes(created, []time.Duration{time.Hour})
+	assert.Error(t, err)
+}